@@ -4,6 +4,7 @@ go 1.21
 
 require (
 	gioui.org v0.4.1
+	github.com/fsnotify/fsnotify v1.7.0
 	github.com/sqweek/dialog v0.0.0-20240226140203-065105509627
 )
 
@@ -15,6 +16,6 @@ require (
 	golang.org/x/exp v0.0.0-20221012211006-4de253d81b95 // indirect
 	golang.org/x/exp/shiny v0.0.0-20220827204233-334a2380cb91 // indirect
 	golang.org/x/image v0.5.0 // indirect
-	golang.org/x/sys v0.0.0-20220825204002-c680a09ffe64 // indirect
+	golang.org/x/sys v0.4.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
 )
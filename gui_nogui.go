@@ -0,0 +1,16 @@
+//go:build nogui
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runGUI em builds com a tag nogui apenas orienta o uso da CLI, permitindo
+// compilar o binário em ambientes sem bibliotecas gráficas.
+func runGUI() {
+	fmt.Fprintln(os.Stderr, "Interface gráfica indisponível neste build (tag nogui).")
+	fmt.Fprintln(os.Stderr, "Use: go-context-generator generate --src <pasta> --out <pasta>")
+	os.Exit(2)
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"strings"
+)
+
+// skeleton retorna o arquivo sem os corpos das funções e métodos: cláusula
+// package, imports, tipos, interfaces, constantes, variáveis e assinaturas.
+// Os comentários seguem as mesmas regras de CleanContent, exceto os que
+// estavam dentro dos corpos removidos.
+func (s *Scanner) skeleton(node *ast.File) string {
+	if node == nil {
+		return ""
+	}
+
+	file := *node
+	file.Decls = make([]ast.Decl, 0, len(node.Decls))
+	var bodies []ast.Node
+	for _, decl := range node.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Body != nil {
+			bodies = append(bodies, fn.Body)
+			stripped := *fn
+			stripped.Body = nil
+			decl = &stripped
+		}
+		file.Decls = append(file.Decls, decl)
+	}
+	file.Comments = commentsOutside(s.fileComments(node), bodies)
+
+	skeleton, err := s.printClean(&file)
+	if err != nil {
+		return ""
+	}
+	return skeleton
+}
+
+// commentsOutside descarta os grupos de comentários contidos em algum dos nós.
+func commentsOutside(comments []*ast.CommentGroup, nodes []ast.Node) []*ast.CommentGroup {
+	var outside []*ast.CommentGroup
+	for _, group := range comments {
+		inside := false
+		for _, node := range nodes {
+			if group.Pos() >= node.Pos() && group.End() <= node.End() {
+				inside = true
+				break
+			}
+		}
+		if !inside {
+			outside = append(outside, group)
+		}
+	}
+	return outside
+}
+
+// apiSurface retorna as declarações exportadas do arquivo sem corpos de
+// funções nem comentários: assinaturas, tipos, constantes e variáveis.
+// Métodos só entram se o tipo do receptor também for exportado.
+func (s *Scanner) apiSurface(node *ast.File) string {
+	if node == nil {
+		return ""
+	}
+
+	var decls []ast.Decl
+	for _, decl := range node.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !decl.Name.IsExported() || (decl.Recv != nil && !exportedReceiver(decl.Recv)) {
+				continue
+			}
+			fn := *decl
+			fn.Doc, fn.Body = nil, nil
+			decls = append(decls, &fn)
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			var specs []ast.Spec
+			for _, spec := range decl.Specs {
+				if !exportedSpec(spec) {
+					continue
+				}
+				if ts, ok := spec.(*ast.TypeSpec); ok {
+					typ := *ts
+					typ.Doc, typ.Comment, typ.Type = nil, nil, apiType(ts.Type)
+					spec = &typ
+				}
+				specs = append(specs, spec)
+			}
+			if len(specs) == 0 {
+				continue
+			}
+			gen := *decl
+			gen.Doc = nil
+			// Em blocos const os valores implícitos (iota) dependem da
+			// posição, então o bloco é mantido inteiro
+			if gen.Tok != token.CONST {
+				gen.Specs = specs
+				if len(specs) == 1 {
+					gen.Lparen, gen.Rparen = token.NoPos, token.NoPos
+				}
+			}
+			decls = append(decls, &gen)
+		}
+	}
+
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	var parts []string
+	for _, decl := range decls {
+		var buf bytes.Buffer
+		if err := cfg.Fprint(&buf, s.fset, decl); err != nil {
+			continue
+		}
+		parts = append(parts, buf.String())
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// apiType reduz structs e interfaces aos membros exportados (e embutidos),
+// sem comentários.
+func apiType(expr ast.Expr) ast.Expr {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		st := *t
+		st.Fields = exportedFields(t.Fields)
+		return &st
+	case *ast.InterfaceType:
+		it := *t
+		it.Methods = exportedFields(t.Methods)
+		return &it
+	}
+	return expr
+}
+
+func exportedFields(fields *ast.FieldList) *ast.FieldList {
+	exported := &ast.FieldList{Opening: fields.Opening, Closing: fields.Closing}
+	for _, field := range fields.List {
+		var names []*ast.Ident
+		for _, name := range field.Names {
+			if name.IsExported() {
+				names = append(names, name)
+			}
+		}
+		if len(field.Names) > 0 && len(names) == 0 {
+			continue
+		}
+		f := *field
+		f.Doc, f.Comment, f.Names = nil, nil, names
+		exported.List = append(exported.List, &f)
+	}
+	return exported
+}
+
+func exportedReceiver(recv *ast.FieldList) bool {
+	return ast.IsExported(receiverName(recv))
+}
+
+func exportedSpec(spec ast.Spec) bool {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return spec.Name.IsExported()
+	case *ast.ValueSpec:
+		for _, name := range spec.Names {
+			if name.IsExported() {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,273 @@
+package analyzer
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// cleanContentForAI remove comentários e compacta o código a partir da AST,
+// reimprimindo o arquivo com go/printer. O resultado sempre é Go válido: se a
+// versão limpa não puder ser reinterpretada, o conteúdo original é mantido.
+func (s *Scanner) cleanContentForAI(content string, node *ast.File) string {
+	if !s.config.RemoveComments && !s.config.MinifyOutput {
+		return content
+	}
+	if node == nil {
+		return content
+	}
+
+	// Cópia rasa: a AST original (com todos os comentários) continua
+	// disponível em GoFile.AST
+	file := *node
+	file.Comments = s.fileComments(node)
+
+	cleaned, err := s.printClean(&file)
+	if err != nil {
+		return content
+	}
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "", cleaned, parser.ParseComments); err != nil {
+		return content
+	}
+
+	return cleaned
+}
+
+// fileComments retorna os comentários do arquivo que sobrevivem à limpeza.
+func (s *Scanner) fileComments(node *ast.File) []*ast.CommentGroup {
+	if s.config.RemoveComments {
+		return s.keptComments(node)
+	}
+	return node.Comments
+}
+
+// printClean imprime um nó com as regras de limpeza: sem alinhamento de
+// colunas e com linhas em branco compactadas quando MinifyOutput está ativo.
+// Para nós que não são *ast.File, use printer.CommentedNode para incluir
+// comentários.
+func (s *Scanner) printClean(node any) (string, error) {
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if s.config.MinifyOutput {
+		// Sem alinhamento de colunas: economiza espaços sem mudar o código
+		cfg = printer.Config{Mode: printer.RawFormat, Tabwidth: 8}
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.Fprint(&buf, s.fset, node); err != nil {
+		return "", err
+	}
+
+	cleaned := buf.String()
+	if s.config.MinifyOutput {
+		cleaned = compactBlankLines(collapseCellTabs(cleaned))
+	}
+	return strings.TrimRight(cleaned, "\n"), nil
+}
+
+// keptComments seleciona os grupos de comentários que sobrevivem à remoção:
+// diretivas (//go:, +build, //line...), o preâmbulo cgo e, se configurado, a
+// documentação do pacote e dos identificadores exportados.
+func (s *Scanner) keptComments(file *ast.File) []*ast.CommentGroup {
+	keep := make(map[*ast.CommentGroup]bool)
+
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			for _, spec := range gen.Specs {
+				if imp := spec.(*ast.ImportSpec); imp.Path.Value == `"C"` {
+					keep[gen.Doc] = true
+					keep[imp.Doc] = true
+				}
+			}
+		}
+	}
+
+	if s.config.KeepExportedDocs {
+		keep[file.Doc] = true
+		markExportedDocs(file, keep)
+	}
+
+	var kept []*ast.CommentGroup
+	for _, group := range file.Comments {
+		if keep[group] {
+			kept = append(kept, group)
+			continue
+		}
+
+		// Dentro de grupos descartados, manter apenas as diretivas
+		var directives []*ast.Comment
+		for _, c := range group.List {
+			if isDirectiveComment(c.Text) {
+				directives = append(directives, c)
+			}
+		}
+		if len(directives) > 0 {
+			kept = append(kept, &ast.CommentGroup{List: directives})
+		}
+	}
+
+	return kept
+}
+
+// markExportedDocs marca os comentários de documentação de declarações,
+// campos e métodos de interface exportados.
+func markExportedDocs(file *ast.File, keep map[*ast.CommentGroup]bool) {
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			if n.Name.IsExported() {
+				keep[n.Doc] = true
+			}
+			return false // Comentários dentro de corpos nunca são documentação
+		case *ast.GenDecl:
+			for _, spec := range n.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if spec.Name.IsExported() {
+						keep[n.Doc] = true
+						keep[spec.Doc] = true
+					}
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						if name.IsExported() {
+							keep[n.Doc] = true
+							keep[spec.Doc] = true
+						}
+					}
+				}
+			}
+		case *ast.Field:
+			for _, name := range n.Names {
+				if name.IsExported() {
+					keep[n.Doc] = true
+				}
+			}
+		}
+		return true
+	})
+
+	delete(keep, nil)
+}
+
+func isDirectiveComment(text string) bool {
+	directives := []string{"//go:", "//line ", "//export ", "//extern ", "//sys ", "//nolint"}
+	for _, prefix := range directives {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return strings.HasPrefix(text, "// +build ")
+}
+
+// collapseCellTabs troca por um espaço os tabs que o RawFormat deixa entre
+// tokens de uma mesma linha (separadores das colunas que o tabwriter
+// alinharia, como em "X, Y\tint" ou "func f()\t{}"). A indentação e o
+// conteúdo de strings e comentários ficam como estão.
+func collapseCellTabs(src string) string {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var sc scanner.Scanner
+	sc.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	var out strings.Builder
+	last := 0
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // Ponto e vírgula automático: não ocupa texto
+		}
+
+		start := file.Offset(pos)
+		gap := src[last:start]
+		if last > 0 && src[last-1] != '\n' && !strings.Contains(gap, "\n") && strings.Contains(gap, "\t") {
+			gap = " "
+		}
+		out.WriteString(gap)
+
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		out.WriteString(src[start:end])
+		last = end
+	}
+	out.WriteString(src[last:])
+
+	return out.String()
+}
+
+// compactBlankLines remove linhas em branco dentro de blocos, mantendo apenas
+// as que separam declarações de nível superior. Linhas dentro de raw strings
+// e comentários de bloco multilinha nunca são alteradas.
+func compactBlankLines(src string) string {
+	lines := strings.Split(src, "\n")
+	protected := multilineTokenLines(src)
+
+	var out []string
+	for i, line := range lines {
+		lineNum := i + 1
+		if protected[lineNum] {
+			out = append(out, line)
+			continue
+		}
+
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(out) == 0 || out[len(out)-1] == "" || !startsTopLevel(lines, i+1) {
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// startsTopLevel indica se a próxima linha não vazia a partir de idx começa
+// uma declaração de nível superior.
+func startsTopLevel(lines []string, idx int) bool {
+	for ; idx < len(lines); idx++ {
+		line := lines[idx]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		return line[0] != ' ' && line[0] != '\t' && line[0] != '}' && line[0] != ')'
+	}
+	return false
+}
+
+// multilineTokenLines retorna as linhas (base 1) cujo fim está dentro de um
+// token que atravessa várias linhas, como raw strings e comentários /* */.
+func multilineTokenLines(src string) map[int]bool {
+	protected := make(map[int]bool)
+
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+
+	var sc scanner.Scanner
+	sc.Init(file, []byte(src), nil, scanner.ScanComments)
+
+	for {
+		pos, tok, lit := sc.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if (tok == token.STRING || tok == token.COMMENT) && strings.Contains(lit, "\n") {
+			start := file.Line(pos)
+			end := start + strings.Count(lit, "\n")
+			for l := start; l < end; l++ {
+				protected[l] = true
+			}
+		}
+	}
+
+	return protected
+}
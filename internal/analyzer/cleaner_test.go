@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"go/parser"
+	"strings"
+	"testing"
+)
+
+func TestMinifyCollapsesCellTabs(t *testing.T) {
+	src := "package p\n\n" +
+		"func H() int { return 1 }\n\n" +
+		"var (\n\tX, Y     int\n\tLongName string = \"a\\tb\" // trailing\n)\n\n" +
+		"type T struct {\n\tA    int    `json:\"a\"`\n\tBcde string\n}\n\n" +
+		"const raw = `x\ty\n\tz`\n\n" +
+		"func unused() {}\n"
+
+	s := NewScanner(ScanConfig{MinifyOutput: true})
+	node, err := parser.ParseFile(s.fset, "p.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := s.cleanContentForAI(src, node)
+
+	for _, want := range []string{
+		"func H() int { return 1 }",
+		"\tX, Y int\n",
+		"\tLongName string = \"a\\tb\" // trailing\n",
+		"\tA int `json:\"a\"`\n",
+		"const raw = `x\ty\n\tz`",
+		"func unused() {}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("saída minificada sem %q:\n%s", want, got)
+		}
+	}
+
+	// Fora da raw string, só a indentação pode ter tabs
+	for _, line := range strings.Split(strings.Replace(got, "`x\ty\n\tz`", "", 1), "\n") {
+		if strings.Contains(strings.TrimLeft(line, "\t"), "\t") {
+			t.Errorf("tab de alinhamento na linha %q", line)
+		}
+	}
+}
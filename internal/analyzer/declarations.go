@@ -0,0 +1,343 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Declaration é uma declaração de nível superior de um arquivo: uma função,
+// um método, um tipo, uma variável ou um bloco const. Uses liga a declaração
+// às declarações locais que ela referencia, resolvidas com go/types.
+type Declaration struct {
+	Name     string // Ex.: "Run", "App.Layout", "Config"
+	Kind     string // "func", "method", "type", "var" ou "const"
+	Code     string // Código limpo, com as mesmas regras de CleanContent
+	Skeleton string // Como Code, mas sem o corpo de funções e métodos
+	Uses     []*Declaration
+}
+
+// resolveDeclarations divide os arquivos em declarações e faz a checagem de
+// tipos de cada pacote local para ligar as referências entre elas. Imports
+// não locais viram pacotes vazios: os erros de tipo resultantes são
+// ignorados, pois apenas as referências a declarações locais interessam.
+// A checagem é interrompida entre pacotes se ctx for cancelado.
+func (s *Scanner) resolveDeclarations(ctx context.Context, files []*GoFile) error {
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	for _, unit := range s.typeCheckUnits(files) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.checkUnit(unit, info)
+	}
+
+	declByObject := make(map[types.Object]*Declaration)
+	nodes := make(map[*Declaration]ast.Node)
+
+	for _, file := range files {
+		header, decls := s.splitDeclarations(file.AST)
+		file.Header = header
+		file.Declarations = nil
+
+		for _, d := range decls {
+			file.Declarations = append(file.Declarations, d.decl)
+			nodes[d.decl] = d.node
+			for _, ident := range d.names {
+				if obj := info.Defs[ident]; obj != nil {
+					declByObject[obj] = d.decl
+				}
+			}
+		}
+	}
+
+	for decl, node := range nodes {
+		seen := map[*Declaration]bool{decl: true}
+		ast.Inspect(node, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			obj := info.Uses[ident]
+			if obj == nil {
+				return true
+			}
+			if used := declByObject[originObject(obj)]; used != nil && !seen[used] {
+				seen[used] = true
+				decl.Uses = append(decl.Uses, used)
+			}
+			return true
+		})
+	}
+	return nil
+}
+
+// typeCheckUnit é um pacote a ser checado: os arquivos de um diretório com
+// o mesmo nome de pacote.
+type typeCheckUnit struct {
+	path     string
+	files    []*GoFile
+	pkg      *types.Package
+	checking bool
+	units    map[string]*typeCheckUnit // Pacotes importáveis por import path
+	fakes    map[string]*types.Package // Pacotes não locais, sem declarações
+}
+
+func (s *Scanner) typeCheckUnits(files []*GoFile) []*typeCheckUnit {
+	byKey := make(map[string]*typeCheckUnit)
+	importable := make(map[string]*typeCheckUnit)
+	fakes := make(map[string]*types.Package)
+	var units []*typeCheckUnit
+
+	for _, file := range files {
+		if file.AST == nil {
+			continue
+		}
+		key := file.ImportPath + "\x00" + file.Package
+		unit, exists := byKey[key]
+		if !exists {
+			unit = &typeCheckUnit{path: file.ImportPath, units: importable, fakes: fakes}
+			if strings.HasSuffix(file.Package, "_test") {
+				unit.path += "_test"
+			} else if _, taken := importable[file.ImportPath]; !taken {
+				importable[file.ImportPath] = unit
+			}
+			byKey[key] = unit
+			units = append(units, unit)
+		}
+		unit.files = append(unit.files, file)
+	}
+
+	sort.Slice(units, func(i, j int) bool { return units[i].path < units[j].path })
+	return units
+}
+
+func (s *Scanner) checkUnit(unit *typeCheckUnit, info *types.Info) *types.Package {
+	if unit.pkg != nil {
+		return unit.pkg
+	}
+	unit.checking = true
+	defer func() { unit.checking = false }()
+
+	conf := types.Config{
+		Importer:    importerFunc(func(path string) (*types.Package, error) { return s.importUnit(unit, path, info) }),
+		Error:       func(error) {}, // Continuar apesar de erros de tipo
+		FakeImportC: true,
+	}
+
+	asts := make([]*ast.File, 0, len(unit.files))
+	for _, file := range unit.files {
+		asts = append(asts, file.AST)
+	}
+
+	unit.pkg, _ = conf.Check(unit.path, s.fset, asts, info)
+	if unit.pkg == nil {
+		unit.pkg = types.NewPackage(unit.path, path.Base(unit.path))
+	}
+	return unit.pkg
+}
+
+func (s *Scanner) importUnit(from *typeCheckUnit, importPath string, info *types.Info) (*types.Package, error) {
+	if unit, local := from.units[importPath]; local {
+		if unit.checking {
+			return nil, fmt.Errorf("ciclo de import: %s", importPath)
+		}
+		return s.checkUnit(unit, info), nil
+	}
+
+	pkg, exists := from.fakes[importPath]
+	if !exists {
+		pkg = types.NewPackage(importPath, path.Base(importPath))
+		pkg.MarkComplete()
+		from.fakes[importPath] = pkg
+	}
+	return pkg, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
+
+// originObject leva métodos e campos de tipos genéricos instanciados de
+// volta ao objeto declarado.
+func originObject(obj types.Object) types.Object {
+	switch obj := obj.(type) {
+	case *types.Func:
+		return obj.Origin()
+	case *types.Var:
+		return obj.Origin()
+	}
+	return obj
+}
+
+// splitDeclaration é uma declaração recém-separada, com o nó de origem e os
+// identificadores que ela define.
+type splitDeclaration struct {
+	decl  *Declaration
+	node  ast.Node
+	names []*ast.Ident
+}
+
+// splitDeclarations imprime a cláusula package com os imports e cada
+// declaração separadamente. Grupos type/var viram uma declaração por spec;
+// blocos const ficam inteiros porque os valores implícitos (iota) dependem
+// da posição.
+func (s *Scanner) splitDeclarations(file *ast.File) (string, []splitDeclaration) {
+	if file == nil {
+		return "", nil
+	}
+
+	comments := s.fileComments(file)
+
+	headerFile := &ast.File{Name: file.Name, Package: file.Package}
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			headerFile.Decls = append(headerFile.Decls, gen)
+		}
+	}
+	if len(headerFile.Decls) > 0 {
+		headerFile.Comments = commentsBetween(comments, file.Pos(), headerFile.Decls[len(headerFile.Decls)-1].End())
+	} else {
+		headerFile.Comments = commentsBetween(comments, file.Pos(), file.Name.End())
+	}
+	header, _ := s.printClean(headerFile)
+
+	var decls []splitDeclaration
+	add := func(kind, name string, node ast.Node, doc *ast.CommentGroup, names []*ast.Ident) {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		code, err := s.printClean(&printer.CommentedNode{Node: node, Comments: commentsBetween(comments, start, node.End())})
+		if err != nil {
+			return
+		}
+
+		skeleton := code
+		if fn, ok := node.(*ast.FuncDecl); ok && fn.Body != nil {
+			stripped := *fn
+			stripped.Body = nil
+			if printed, err := s.printClean(&printer.CommentedNode{Node: &stripped, Comments: commentsBetween(comments, start, fn.Type.End())}); err == nil {
+				skeleton = printed
+			}
+		}
+
+		decls = append(decls, splitDeclaration{
+			decl:  &Declaration{Name: name, Kind: kind, Code: code, Skeleton: skeleton},
+			node:  node,
+			names: names,
+		})
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			kind, name := "func", decl.Name.Name
+			if decl.Recv != nil {
+				kind, name = "method", receiverName(decl.Recv)+"."+name
+			}
+			add(kind, name, decl, decl.Doc, []*ast.Ident{decl.Name})
+
+		case *ast.GenDecl:
+			if decl.Tok == token.IMPORT {
+				continue
+			}
+			if decl.Tok == token.CONST || !decl.Lparen.IsValid() || len(decl.Specs) == 1 {
+				var names []*ast.Ident
+				for _, spec := range decl.Specs {
+					names = append(names, specNames(spec)...)
+				}
+				add(decl.Tok.String(), identNames(names), decl, decl.Doc, names)
+				continue
+			}
+
+			// A documentação de cada spec sobe para a declaração, para ser
+			// impressa antes da palavra-chave
+			for _, spec := range decl.Specs {
+				spec, doc := detachSpecDoc(spec)
+				single := &ast.GenDecl{Doc: doc, Tok: decl.Tok, TokPos: spec.Pos(), Specs: []ast.Spec{spec}}
+				names := specNames(spec)
+				add(decl.Tok.String(), identNames(names), single, doc, names)
+			}
+		}
+	}
+
+	return header, decls
+}
+
+// commentsBetween filtra os grupos de comentários contidos em [start, end].
+func commentsBetween(comments []*ast.CommentGroup, start, end token.Pos) []*ast.CommentGroup {
+	var selected []*ast.CommentGroup
+	for _, group := range comments {
+		if group.Pos() >= start && group.End() <= end {
+			selected = append(selected, group)
+		}
+	}
+	return selected
+}
+
+func specNames(spec ast.Spec) []*ast.Ident {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		return []*ast.Ident{spec.Name}
+	case *ast.ValueSpec:
+		return spec.Names
+	}
+	return nil
+}
+
+// detachSpecDoc retorna uma cópia da spec sem a documentação, e a documentação.
+func detachSpecDoc(spec ast.Spec) (ast.Spec, *ast.CommentGroup) {
+	switch spec := spec.(type) {
+	case *ast.TypeSpec:
+		detached := *spec
+		detached.Doc = nil
+		return &detached, spec.Doc
+	case *ast.ValueSpec:
+		detached := *spec
+		detached.Doc = nil
+		return &detached, spec.Doc
+	}
+	return spec, nil
+}
+
+func identNames(idents []*ast.Ident) string {
+	names := make([]string, len(idents))
+	for i, ident := range idents {
+		names[i] = ident.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// receiverName retorna o nome do tipo do receptor, sem ponteiro nem parâmetros de tipo.
+func receiverName(recv *ast.FieldList) string {
+	if len(recv.List) == 0 {
+		return ""
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.ParenExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
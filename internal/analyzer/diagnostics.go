@@ -0,0 +1,119 @@
+package analyzer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Diagnostic descreve um arquivo Go que não pôde ser interpretado.
+type Diagnostic struct {
+	Path     string // Relativo à raiz do projeto, separado por "/"
+	Reason   string // "syntax error", "merge conflict" ou "read error"
+	Line     int    // Posição do primeiro erro; 0 se desconhecida
+	Column   int
+	Errors   []string // Erros do parser, no formato "linha:coluna: mensagem"
+	Included bool     // Incluído no contexto como código bruto (IncludeUnparseable)
+}
+
+// Position formata a posição do primeiro erro como "caminho:linha:coluna".
+func (d Diagnostic) Position() string {
+	if d.Line == 0 {
+		return d.Path
+	}
+	return fmt.Sprintf("%s:%d:%d", d.Path, d.Line, d.Column)
+}
+
+// Diagnostics retorna os arquivos que o último ScanDirectory não conseguiu
+// interpretar, na ordem do percurso.
+func (s *Scanner) Diagnostics() []Diagnostic {
+	return s.diagnostics
+}
+
+// diagnose descreve a falha de parseGoFile. Marcadores de conflito de merge
+// têm prioridade sobre os erros de sintaxe que eles provocam.
+func (s *Scanner) diagnose(path string, err error) Diagnostic {
+	diag := Diagnostic{Path: s.relPath(path), Reason: "read error"}
+
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		diag.Errors = []string{err.Error()}
+		return diag
+	}
+
+	diag.Reason = "syntax error"
+	for _, e := range list {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("%d:%d: %s", e.Pos.Line, e.Pos.Column, e.Msg))
+	}
+	if len(list) > 0 {
+		diag.Line, diag.Column = list[0].Pos.Line, list[0].Pos.Column
+	}
+
+	if line := conflictMarkerLine(path); line > 0 {
+		diag.Reason = "merge conflict"
+		diag.Line, diag.Column = line, 1
+	}
+	return diag
+}
+
+// conflictMarkerLine retorna a linha do primeiro marcador de conflito de
+// merge ("<<<<<<< ", "=======", ">>>>>>> "), ou 0 se não houver.
+func conflictMarkerLine(path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	lines.Buffer(nil, 1<<20)
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimRight(lines.Text(), "\r")
+		if strings.HasPrefix(line, "<<<<<<< ") || line == "=======" || strings.HasPrefix(line, ">>>>>>> ") {
+			return n
+		}
+	}
+	return 0
+}
+
+// rawGoFile monta um GoFile com o código original de um arquivo que não pôde
+// ser interpretado. O pacote e os imports vêm do que o parser conseguir ler
+// do cabeçalho; sem AST, o arquivo não tem API, esqueleto nem declarações.
+func (s *Scanner) rawGoFile(path string) *GoFile {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	goFile := &GoFile{
+		Path:         path,
+		Name:         filepath.Base(path),
+		Package:      filepath.Base(filepath.Dir(path)),
+		Content:      string(content),
+		CleanContent: string(content),
+		Size:         int64(len(content)),
+		LOC:          s.countLines(string(content)),
+		Unparseable:  true,
+	}
+
+	// Um FileSet próprio evita registrar o arquivo duas vezes em s.fset
+	header, _ := parser.ParseFile(token.NewFileSet(), path, content, parser.ImportsOnly)
+	if header != nil {
+		if header.Name != nil && header.Name.Name != "" && header.Name.Name != "_" {
+			goFile.Package = header.Name.Name
+		}
+		for _, imp := range header.Imports {
+			if imp.Path != nil {
+				goFile.Imports = append(goFile.Imports, strings.Trim(imp.Path.Value, `"`))
+			}
+		}
+	}
+
+	return goFile
+}
@@ -0,0 +1,290 @@
+package analyzer
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Arquivos de regras de exclusão lidos em cada diretório do projeto
+const (
+	gitignoreFile     = ".gitignore"
+	contextignoreFile = ".contextignore"
+)
+
+// ignoreRule é uma linha de um arquivo .gitignore/.contextignore já compilada.
+type ignoreRule struct {
+	pattern string // padrão original, como escrito no arquivo
+	source  string // arquivo de origem, relativo à raiz do projeto
+	line    int
+	negate  bool
+	dirOnly bool
+	re      *regexp.Regexp
+}
+
+// String descreve a regra no formato "arquivo:linha: padrão".
+func (r *ignoreRule) String() string {
+	return fmt.Sprintf("%s:%d: %s", r.source, r.line, r.pattern)
+}
+
+// ignoreMatcher guarda as regras de todos os diretórios visitados e aplica a
+// semântica do gitignore: regras de diretórios mais profundos têm precedência
+// e, dentro de um mesmo arquivo, a última regra que casa vence.
+type ignoreMatcher struct {
+	rules map[string][]*ignoreRule // diretório relativo -> regras
+}
+
+func newIgnoreMatcher() *ignoreMatcher {
+	return &ignoreMatcher{rules: make(map[string][]*ignoreRule)}
+}
+
+// loadDir lê os arquivos de exclusão de um diretório. O .contextignore é
+// carregado depois do .gitignore, então suas regras têm precedência.
+func (m *ignoreMatcher) loadDir(root, relDir string, includeGitignore bool) error {
+	var names []string
+	if includeGitignore {
+		names = append(names, gitignoreFile)
+	}
+	names = append(names, contextignoreFile)
+
+	for _, name := range names {
+		rules, err := parseIgnoreFile(filepath.Join(root, filepath.FromSlash(relDir), name), path.Join(relDir, name))
+		if err != nil {
+			return err
+		}
+		m.rules[relDir] = append(m.rules[relDir], rules...)
+	}
+	return nil
+}
+
+// match verifica se o caminho (relativo à raiz, separado por "/") é ignorado e
+// retorna a regra decisiva. Uma regra de negação decisiva retorna ignored=false.
+func (m *ignoreMatcher) match(relPath string, isDir bool) (*ignoreRule, bool) {
+	var decisive *ignoreRule
+
+	// Percorrer da raiz até o diretório pai, para que regras mais profundas
+	// sobrescrevam as mais rasas
+	dirs := []string{""}
+	parent := path.Dir(relPath)
+	if parent != "." {
+		parts := strings.Split(parent, "/")
+		for i := range parts {
+			dirs = append(dirs, strings.Join(parts[:i+1], "/"))
+		}
+	}
+
+	for _, dir := range dirs {
+		for _, rule := range m.rules[dir] {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			target := relPath
+			if dir != "" {
+				target = strings.TrimPrefix(relPath, dir+"/")
+			}
+			if rule.re.MatchString(target) {
+				decisive = rule
+			}
+		}
+	}
+
+	if decisive == nil {
+		return nil, false
+	}
+	return decisive, !decisive.negate
+}
+
+func parseIgnoreFile(filePath, source string) ([]*ignoreRule, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules []*ignoreRule
+	lineNum := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		lineNum++
+		rule := parseIgnoreLine(sc.Text())
+		if rule == nil {
+			continue
+		}
+		rule.source = source
+		rule.line = lineNum
+		rules = append(rules, rule)
+	}
+
+	return rules, sc.Err()
+}
+
+func parseIgnoreLine(line string) *ignoreRule {
+	line = strings.TrimSuffix(line, "\r")
+	original := line
+
+	// Espaços no final são ignorados, a menos que escapados com "\"
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, "\\ ") {
+		line = line[:len(line)-1]
+	}
+
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	rule := &ignoreRule{pattern: strings.TrimSpace(original)}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimSuffix(line, "/")
+	}
+
+	if line == "" {
+		return nil
+	}
+
+	// Padrões sem "/" (exceto no final) casam em qualquer nível
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	if !anchored && !strings.HasPrefix(line, "**") {
+		line = "**/" + line
+	}
+
+	re, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return nil
+	}
+	rule.re = re
+
+	return rule
+}
+
+// globToRegexp converte um padrão no estilo gitignore/doublestar em uma
+// expressão regular. "*" não atravessa "/", enquanto "**" casa qualquer
+// número de diretórios.
+func globToRegexp(pattern string) string {
+	var sb strings.Builder
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				atStart := i == 0 || pattern[i-1] == '/'
+				atEnd := i+2 == len(pattern)
+				followedBySlash := i+2 < len(pattern) && pattern[i+2] == '/'
+
+				switch {
+				case atStart && followedBySlash:
+					// "**/" casa zero ou mais diretórios
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				case atStart && atEnd:
+					sb.WriteString(".*")
+					i++
+				default:
+					sb.WriteString("[^/]*")
+					i++
+				}
+				continue
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return sb.String()
+}
+
+// pathPattern é um padrão de inclusão/exclusão configurado pelo usuário.
+type pathPattern struct {
+	raw     string
+	include bool
+	re      *regexp.Regexp
+}
+
+// compilePathPatterns compila a lista ordenada de padrões do ScanConfig.
+// Cada entrada começa com "+" (incluir) ou "-" (excluir); sem prefixo, o
+// padrão exclui. Os padrões seguem a sintaxe doublestar e casam com o caminho
+// completo relativo à raiz; "dir/**" casa também o próprio diretório.
+func compilePathPatterns(patterns []string) ([]*pathPattern, error) {
+	var compiled []*pathPattern
+
+	for _, raw := range patterns {
+		glob := strings.TrimSpace(raw)
+		if glob == "" {
+			continue
+		}
+
+		p := &pathPattern{raw: glob}
+		switch glob[0] {
+		case '+':
+			p.include = true
+			glob = glob[1:]
+		case '-':
+			glob = glob[1:]
+		}
+		glob = strings.TrimPrefix(strings.TrimSpace(glob), "/")
+		if glob == "" {
+			return nil, fmt.Errorf("padrão vazio: %q", raw)
+		}
+
+		expr := globToRegexp(glob)
+		if strings.HasSuffix(glob, "/**") {
+			expr = globToRegexp(strings.TrimSuffix(glob, "/**")) + "(?:/.*)?"
+		}
+
+		re, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			return nil, fmt.Errorf("padrão inválido %q: %w", raw, err)
+		}
+		p.re = re
+		compiled = append(compiled, p)
+	}
+
+	return compiled, nil
+}
+
+// matchPathPatterns aplica os padrões com a semântica "último que casa vence".
+// Retorna matched=false quando nenhum padrão casa com o caminho.
+func matchPathPatterns(patterns []*pathPattern, relPath string) (p *pathPattern, matched bool) {
+	for _, candidate := range patterns {
+		if candidate.re.MatchString(relPath) {
+			p = candidate
+		}
+	}
+	return p, p != nil
+}
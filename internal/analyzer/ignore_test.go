@@ -0,0 +1,212 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob  string
+		path  string
+		match bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false}, // "*" não atravessa "/"
+		{"a?c", "abc", true},
+		{"a?c", "a/c", false},
+		{"[ab].go", "b.go", true},
+		{"[!ab].go", "a.go", false},
+		{"[!ab].go", "c.go", true},
+		{`\*.go`, "*.go", true},
+		{`\*.go`, "x.go", false},
+		{"**/gen", "gen", true}, // "**/" casa zero diretórios
+		{"**/gen", "a/b/gen", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/c", false},
+		{"a/**", "a/x/y.go", true},
+		{"**", "qualquer/coisa", true},
+		{"x**y", "xAy", true}, // "**" no meio de um nome vale como "*"
+		{"x**y", "x/y", false},
+		{"file.go", "fileXgo", false}, // "." é literal
+	}
+
+	for _, tt := range tests {
+		re := regexp.MustCompile("^" + globToRegexp(tt.glob) + "$")
+		if got := re.MatchString(tt.path); got != tt.match {
+			t.Errorf("globToRegexp(%q) casa %q = %v, esperado %v", tt.glob, tt.path, got, tt.match)
+		}
+	}
+}
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		skip    bool // Linha sem regra
+		negate  bool
+		dirOnly bool
+		matches []string
+		misses  []string
+	}{
+		{line: "", skip: true},
+		{line: "# comentário", skip: true},
+		{line: "   ", skip: true},
+		{line: "/", skip: true},
+		{line: "*.pb.go", matches: []string{"api.pb.go", "a/b/api.pb.go"}, misses: []string{"api.go"}},
+		{line: "gen", matches: []string{"gen", "a/gen"}, misses: []string{"generated"}},
+		{line: "/gen", matches: []string{"gen"}, misses: []string{"a/gen"}}, // "/" inicial ancora na pasta do arquivo
+		{line: "a/gen", matches: []string{"a/gen"}, misses: []string{"x/a/gen"}},
+		{line: "build/", dirOnly: true, matches: []string{"build", "x/build"}},
+		{line: "/build/", dirOnly: true, matches: []string{"build"}, misses: []string{"x/build"}},
+		{line: "!keep.go", negate: true, matches: []string{"keep.go", "a/keep.go"}},
+		{line: `\!bang.go`, matches: []string{"!bang.go"}},
+		{line: `\#hash.go`, matches: []string{"#hash.go"}},
+		{line: "trailing.go   ", matches: []string{"trailing.go"}},
+		{line: `space\ `, matches: []string{"space "}},
+		{line: "**/testdata", matches: []string{"testdata", "a/b/testdata"}},
+		{line: "docs/**/*.go", matches: []string{"docs/x.go", "docs/a/b/x.go"}, misses: []string{"x/docs/x.go"}},
+		{line: "mocks/**", matches: []string{"mocks/a.go", "mocks/a/b.go"}, misses: []string{"mocks", "x/mocks/a.go"}},
+		{line: "crlf.go\r", matches: []string{"crlf.go"}},
+	}
+
+	for _, tt := range tests {
+		rule := parseIgnoreLine(tt.line)
+		if tt.skip {
+			if rule != nil {
+				t.Errorf("parseIgnoreLine(%q) = %v, esperado nenhuma regra", tt.line, rule.re)
+			}
+			continue
+		}
+		if rule == nil {
+			t.Errorf("parseIgnoreLine(%q) = nil", tt.line)
+			continue
+		}
+		if rule.negate != tt.negate || rule.dirOnly != tt.dirOnly {
+			t.Errorf("parseIgnoreLine(%q): negate=%v dirOnly=%v, esperado %v %v", tt.line, rule.negate, rule.dirOnly, tt.negate, tt.dirOnly)
+		}
+		for _, path := range tt.matches {
+			if !rule.re.MatchString(path) {
+				t.Errorf("%q deveria casar %q (%s)", tt.line, path, rule.re)
+			}
+		}
+		for _, path := range tt.misses {
+			if rule.re.MatchString(path) {
+				t.Errorf("%q não deveria casar %q (%s)", tt.line, path, rule.re)
+			}
+		}
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".gitignore", "*.pb.go\ngen/\n!keep/\nlocal.go\n")
+	write(".contextignore", "!api.pb.go\nbig_*.go\nlocal.go\n!local.go\n")
+	write("svc/.gitignore", "!*.pb.go\n/only_here.go\n")
+	write("svc/.contextignore", "internal.pb.go\n")
+
+	load := func(includeGitignore bool) *ignoreMatcher {
+		m := newIgnoreMatcher()
+		for _, dir := range []string{"", "svc"} {
+			if err := m.loadDir(root, dir, includeGitignore); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return m
+	}
+
+	tests := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+		rule    string // Regra decisiva; vazio quando nenhuma casa
+	}{
+		{"types.pb.go", false, true, ".gitignore:1: *.pb.go"},
+		{"api.pb.go", false, false, ".contextignore:1: !api.pb.go"}, // .contextignore vence o .gitignore
+		{"local.go", false, false, ".contextignore:4: !local.go"},   // Última regra que casa vence
+		{"big_table.go", false, true, ".contextignore:2: big_*.go"},
+		{"gen", true, true, ".gitignore:2: gen/"},
+		{"gen", false, false, ""}, // "gen/" só vale para diretórios
+		{"keep", true, false, ".gitignore:3: !keep/"},
+		{"svc/types.pb.go", false, false, "svc/.gitignore:1: !*.pb.go"}, // Pasta mais profunda vence
+		{"svc/internal.pb.go", false, true, "svc/.contextignore:1: internal.pb.go"},
+		{"svc/only_here.go", false, true, "svc/.gitignore:2: /only_here.go"},
+		{"svc/sub/only_here.go", false, false, ""}, // Ancorado na pasta do .gitignore
+		{"main.go", false, false, ""},
+	}
+
+	m := load(true)
+	for _, tt := range tests {
+		rule, ignored := m.match(tt.path, tt.isDir)
+		got := ""
+		if rule != nil {
+			got = rule.String()
+		}
+		if ignored != tt.ignored || got != tt.rule {
+			t.Errorf("match(%q, dir=%v) = %q, %v; esperado %q, %v", tt.path, tt.isDir, got, ignored, tt.rule, tt.ignored)
+		}
+	}
+
+	// Sem o .gitignore, apenas o .contextignore vale
+	m = load(false)
+	if _, ignored := m.match("types.pb.go", false); ignored {
+		t.Error("types.pb.go ignorado sem ler o .gitignore")
+	}
+	if _, ignored := m.match("svc/internal.pb.go", false); !ignored {
+		t.Error("svc/internal.pb.go deveria ser ignorado pelo .contextignore")
+	}
+}
+
+func TestMatchPathPatterns(t *testing.T) {
+	patterns, err := compilePathPatterns([]string{
+		"-internal/**",
+		"+internal/api/**",
+		"**/*_gen.go",
+		"+/internal/api/keep_gen.go",
+		"  ",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patterns) != 4 {
+		t.Fatalf("%d padrões compilados, esperados 4", len(patterns))
+	}
+
+	tests := []struct {
+		path    string
+		matched bool
+		include bool
+	}{
+		{"main.go", false, false},
+		{"internal", true, false}, // "dir/**" casa o próprio diretório
+		{"internal/db/db.go", true, false},
+		{"internal/api", true, true},
+		{"internal/api/handler.go", true, true},
+		{"internal/api/types_gen.go", true, false},
+		{"internal/api/keep_gen.go", true, true},
+		{"cmd/tool_gen.go", true, false},
+	}
+	for _, tt := range tests {
+		p, matched := matchPathPatterns(patterns, tt.path)
+		if matched != tt.matched || (matched && p.include != tt.include) {
+			t.Errorf("matchPathPatterns(%q) = %v, %v; esperado %v, include=%v", tt.path, p, matched, tt.matched, tt.include)
+		}
+	}
+
+	for _, bad := range []string{"+", "-/", "[z-a].go"} {
+		if _, err := compilePathPatterns([]string{bad}); err == nil {
+			t.Errorf("compilePathPatterns(%q) deveria falhar", bad)
+		}
+	}
+}
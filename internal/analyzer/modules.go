@@ -0,0 +1,208 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// moduleResolver mapeia import paths para diretórios locais. Conhece os
+// módulos cujos go.mod foram encontrados no escaneamento (incluindo módulos
+// aninhados), os listados no go.work e os destinos locais de diretivas replace.
+type moduleResolver struct {
+	modules map[string]string // Caminho do módulo → diretório absoluto
+}
+
+func newModuleResolver() *moduleResolver {
+	return &moduleResolver{modules: make(map[string]string)}
+}
+
+// loadWorkspace procura um go.work na raiz ou nos diretórios acima dela, como
+// o comando go, e registra os módulos das diretivas use. GOWORK=off desativa.
+func (r *moduleResolver) loadWorkspace(root string) {
+	workFile := os.Getenv("GOWORK")
+	if workFile == "off" {
+		return
+	}
+
+	if workFile == "" {
+		dir, err := filepath.Abs(root)
+		if err != nil {
+			return
+		}
+		for {
+			candidate := filepath.Join(dir, "go.work")
+			if _, err := os.Stat(candidate); err == nil {
+				workFile = candidate
+				break
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				return
+			}
+			dir = parent
+		}
+	}
+
+	content, err := os.ReadFile(workFile)
+	if err != nil {
+		return
+	}
+
+	workDir := filepath.Dir(workFile)
+	for _, args := range modDirectives(string(content), "use") {
+		if len(args) > 0 {
+			r.addModule(resolveModDir(workDir, args[0]))
+		}
+	}
+	r.addReplaces(workDir, string(content))
+}
+
+// addModule lê o go.mod de dir, se existir, e registra o módulo e os
+// destinos locais de suas diretivas replace.
+func (r *moduleResolver) addModule(dir string) {
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return
+	}
+
+	for _, args := range modDirectives(string(content), "module") {
+		if len(args) > 0 {
+			r.register(args[0], dir)
+		}
+	}
+	r.addReplaces(dir, string(content))
+}
+
+// addReplaces registra "replace antigo [versão] => ./caminho"; substituições
+// por outras versões de módulos remotos não apontam para código local.
+func (r *moduleResolver) addReplaces(baseDir, content string) {
+	for _, args := range modDirectives(content, "replace") {
+		arrow := -1
+		for i, arg := range args {
+			if arg == "=>" {
+				arrow = i
+			}
+		}
+		if arrow < 1 || arrow+1 >= len(args) {
+			continue
+		}
+
+		target := args[arrow+1]
+		if filepath.IsAbs(target) || strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") ||
+			strings.HasPrefix(target, `.\`) || strings.HasPrefix(target, `..\`) {
+			r.register(args[0], resolveModDir(baseDir, target))
+		}
+	}
+}
+
+// register associa um caminho de módulo a um diretório, sem sobrescrever um
+// registro anterior: o go.work e os replaces têm precedência.
+func (r *moduleResolver) register(modulePath, dir string) {
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	if _, exists := r.modules[modulePath]; !exists {
+		r.modules[modulePath] = dir
+	}
+}
+
+// dir retorna o diretório de um import path, pelo módulo de prefixo mais longo.
+func (r *moduleResolver) dir(importPath string) (string, bool) {
+	best := ""
+	for modulePath := range r.modules {
+		if (importPath == modulePath || strings.HasPrefix(importPath, modulePath+"/")) && len(modulePath) > len(best) {
+			best = modulePath
+		}
+	}
+	if best == "" {
+		return "", false
+	}
+
+	rest := strings.TrimPrefix(importPath, best)
+	return filepath.Join(r.modules[best], filepath.FromSlash(rest)), true
+}
+
+// importPath faz o caminho inverso: o import path do pacote em dir, pelo
+// módulo de diretório mais interno.
+func (r *moduleResolver) importPath(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+
+	bestPath, bestDir := "", ""
+	for modulePath, modDir := range r.modules {
+		rel, err := filepath.Rel(modDir, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if len(modDir) > len(bestDir) || (len(modDir) == len(bestDir) && modulePath < bestPath) {
+			bestPath, bestDir = modulePath, modDir
+		}
+	}
+	if bestDir == "" {
+		return "", false
+	}
+
+	rel, _ := filepath.Rel(bestDir, abs)
+	if rel == "." {
+		return bestPath, true
+	}
+	return bestPath + "/" + filepath.ToSlash(rel), true
+}
+
+// modDirectives extrai os argumentos de cada ocorrência de uma diretiva em
+// go.mod/go.work, tanto na forma de linha única quanto em blocos "( ... )".
+func modDirectives(content, verb string) [][]string {
+	var entries [][]string
+	inBlock := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		if inBlock {
+			if fields[0] == ")" {
+				inBlock = false
+				continue
+			}
+			entries = append(entries, unquoteFields(fields))
+			continue
+		}
+
+		if fields[0] != verb {
+			continue
+		}
+		if len(fields) == 2 && fields[1] == "(" {
+			inBlock = true
+			continue
+		}
+		entries = append(entries, unquoteFields(fields[1:]))
+	}
+
+	return entries
+}
+
+func unquoteFields(fields []string) []string {
+	for i, field := range fields {
+		if unquoted, err := strconv.Unquote(field); err == nil {
+			fields[i] = unquoted
+		}
+	}
+	return fields
+}
+
+func resolveModDir(baseDir, path string) string {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(baseDir, path)
+}
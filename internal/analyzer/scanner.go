@@ -1,38 +1,93 @@
 package analyzer
 
 import (
+	"context"
 	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"regexp"
+	"sort"
 	"strings"
+	"time"
+
+	"go-context-generator/internal/parallel"
 )
 
 type ScanConfig struct {
-	IncludeTests   bool
-	RemoveComments bool
-	MinifyOutput   bool
+	IncludeTests     bool
+	RemoveComments   bool
+	KeepExportedDocs bool // Com RemoveComments, manter a documentação do pacote e dos identificadores exportados
+	MinifyOutput     bool
+	RespectGitignore bool     // Ler .gitignore aninhados além do .contextignore
+	PathPatterns     []string // Globs "+incluir"/"-excluir", o último que casa vence
+
+	// Alvo da resolução de dependências; vazios usam os valores do ambiente
+	GOOS      string
+	GOARCH    string
+	BuildTags []string
+
+	// SliceDependencies faz a checagem de tipos dos pacotes locais e preenche
+	// GoFile.Declarations, usado para incluir só as declarações referenciadas
+	SliceDependencies bool
+
+	// Workers limita as goroutines que interpretam arquivos; 0 usa GOMAXPROCS
+	Workers int
+
+	// IncludeUnparseable mantém arquivos com erro de sintaxe como código bruto
+	// (GoFile.Unparseable); sem ela, eles aparecem apenas em Diagnostics
+	IncludeUnparseable bool
 }
 
 type Scanner struct {
-	config ScanConfig
-	fset   *token.FileSet
+	config   ScanConfig
+	fset     *token.FileSet
+	root     string
+	ignore   *ignoreMatcher
+	patterns []*pathPattern
+	skipped  []SkippedPath
+	modules  *moduleResolver
+
+	diagnostics []Diagnostic
+	cache       map[string]cachedFile // Arquivos interpretados no ScanDirectory anterior
+}
+
+// cachedFile permite reaproveitar um arquivo interpretado enquanto o tamanho
+// e a data de modificação não mudarem.
+type cachedFile struct {
+	file    *GoFile
+	size    int64
+	modTime time.Time
+}
+
+// SkippedPath registra um diretório ou arquivo Go excluído do escaneamento e
+// a regra responsável pela exclusão.
+type SkippedPath struct {
+	Path  string // Relativo à raiz do projeto, separado por "/"
+	IsDir bool
+	Rule  string // Ex.: ".gitignore:3: build/", "pattern: -**/zz_*.go" ou "built-in: vendor"
 }
 
 type GoFile struct {
 	Path         string
 	Name         string
 	Package      string
+	ImportPath   string // Import path do pacote, resolvido pelo go.mod mais interno
 	Imports      []string
+	LocalImports []string // Imports resolvidos para código local (módulos do projeto, go.work, replace)
 	Dependencies []string
 	Content      string
 	CleanContent string
+	API          string // Declarações exportadas, sem corpos (ver apiSurface)
+	Skeleton     string // Arquivo inteiro sem corpos de funções (ver skeleton)
+	Header       string // Cláusula package e imports limpos (com SliceDependencies)
+	Declarations []*Declaration
 	AST          *ast.File
 	Size         int64
-	LOC          int // Lines of Code
+	LOC          int  // Lines of Code
+	Unparseable  bool // Erro de sintaxe: Content e CleanContent são o código original, sem AST
 }
 
 func NewScanner(config ScanConfig) *Scanner {
@@ -42,13 +97,34 @@ func NewScanner(config ScanConfig) *Scanner {
 	}
 }
 
-func (s *Scanner) ScanDirectory(dir string) ([]*GoFile, error) {
-	var files []*GoFile
+// ScanDirectory percorre dir e interpreta os arquivos Go encontrados. Se ctx
+// for cancelado, a varredura para assim que possível e retorna ctx.Err().
+// Arquivos que não mudaram desde o ScanDirectory anterior do mesmo Scanner
+// não são interpretados de novo.
+func (s *Scanner) ScanDirectory(ctx context.Context, dir string) ([]*GoFile, error) {
+	var paths []string
+	var infos []fs.FileInfo
+
+	s.root = dir
+	s.ignore = newIgnoreMatcher()
+	s.skipped = nil
+	s.diagnostics = nil
+	s.modules = newModuleResolver()
+	s.modules.loadWorkspace(dir)
+
+	patterns, err := compilePathPatterns(s.config.PathPatterns)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns = patterns
 
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
 		if s.shouldSkipPath(path, d) {
 			if d.IsDir() {
@@ -57,14 +133,21 @@ func (s *Scanner) ScanDirectory(dir string) ([]*GoFile, error) {
 			return nil
 		}
 
+		// Carregar regras de exclusão do diretório antes de visitar seu conteúdo
+		if d.IsDir() {
+			if err := s.ignore.loadDir(dir, s.relPath(path), s.config.RespectGitignore); err != nil {
+				return err
+			}
+			s.modules.addModule(path)
+		}
+
 		if !d.IsDir() && strings.HasSuffix(path, ".go") {
-			goFile, err := s.parseGoFile(path)
+			info, err := d.Info()
 			if err != nil {
-				// Log error mas continua o processamento
-				return nil
+				return err
 			}
-
-			files = append(files, goFile)
+			paths = append(paths, path)
+			infos = append(infos, info)
 		}
 
 		return nil
@@ -74,13 +157,134 @@ func (s *Scanner) ScanDirectory(dir string) ([]*GoFile, error) {
 		return nil, err
 	}
 
+	// Interpretar em paralelo, mantendo a ordem do percurso
+	parsed := make([]*GoFile, len(paths))
+	failures := make([]*Diagnostic, len(paths))
+	err = parallel.ForEach(ctx, len(paths), s.config.Workers, func(i int) error {
+		if cached, ok := s.cache[paths[i]]; ok && cached.size == infos[i].Size() && cached.modTime.Equal(infos[i].ModTime()) {
+			parsed[i] = cached.file
+			return nil
+		}
+
+		file, err := s.parseGoFile(paths[i])
+		if err != nil {
+			diag := s.diagnose(paths[i], err)
+			if s.config.IncludeUnparseable {
+				file = s.rawGoFile(paths[i])
+				diag.Included = file != nil
+			}
+			failures[i] = &diag
+		}
+		parsed[i] = file
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, diag := range failures {
+		if diag != nil {
+			s.diagnostics = append(s.diagnostics, *diag)
+		}
+	}
+
+	// Arquivos brutos (com erro) são sempre interpretados de novo, para que
+	// continuem em Diagnostics
+	s.cache = make(map[string]cachedFile, len(parsed))
+	for i, file := range parsed {
+		if file != nil && !file.Unparseable {
+			s.cache[paths[i]] = cachedFile{file: file, size: infos[i].Size(), modTime: infos[i].ModTime()}
+		}
+	}
+
+	files := make([]*GoFile, 0, len(parsed))
+	for _, file := range parsed {
+		if file != nil {
+			files = append(files, file)
+		}
+	}
+
 	// Resolver dependências entre arquivos
 	s.resolveDependencies(files, dir)
 
+	if s.config.SliceDependencies {
+		if err := s.resolveDeclarations(ctx, files); err != nil {
+			return nil, err
+		}
+	}
+
 	return files, nil
 }
 
+// SkippedPaths retorna os caminhos excluídos no último ScanDirectory, com a
+// regra que excluiu cada um. Arquivos que não são .go não são listados.
+func (s *Scanner) SkippedPaths() []SkippedPath {
+	return s.skipped
+}
+
 func (s *Scanner) shouldSkipPath(path string, d fs.DirEntry) bool {
+	rule, skip := s.skipReason(path, d)
+	if skip && rule != "" {
+		s.skipped = append(s.skipped, SkippedPath{
+			Path:  s.relPath(path),
+			IsDir: d.IsDir(),
+			Rule:  rule,
+		})
+	}
+	return skip
+}
+
+// ShouldSkip informa se o caminho seria excluído pelo escaneamento, com as
+// mesmas regras e os arquivos de exclusão carregados no último ScanDirectory.
+// O caminho não precisa existir, o que permite avaliar arquivos removidos.
+func (s *Scanner) ShouldSkip(path string, isDir bool) bool {
+	_, skip := s.skipReason(path, pathEntry{name: filepath.Base(path), dir: isDir})
+	return skip
+}
+
+// pathEntry é um fs.DirEntry mínimo para caminhos fora de um percurso.
+type pathEntry struct {
+	name string
+	dir  bool
+}
+
+func (e pathEntry) Name() string { return e.name }
+func (e pathEntry) IsDir() bool  { return e.dir }
+func (e pathEntry) Type() fs.FileMode {
+	if e.dir {
+		return fs.ModeDir
+	}
+	return 0
+}
+func (e pathEntry) Info() (fs.FileInfo, error) { return nil, fs.ErrNotExist }
+
+// skipReason decide se o caminho deve ser ignorado e descreve a regra
+// responsável. Uma regra vazia indica exclusão que não precisa ser reportada.
+// Os padrões do usuário têm a palavra final sobre as regras padrão.
+func (s *Scanner) skipReason(path string, d fs.DirEntry) (string, bool) {
+	relPath := s.relPath(path)
+
+	if relPath == "" {
+		return "", false // Nunca pular a raiz do projeto
+	}
+
+	// Apenas arquivos .go são processados, independentemente dos padrões
+	if !d.IsDir() && !strings.HasSuffix(path, ".go") {
+		return "", true
+	}
+
+	if p, matched := matchPathPatterns(s.patterns, relPath); matched {
+		if p.include {
+			return "", false
+		}
+		return "pattern: " + p.raw, true
+	}
+
+	return s.defaultSkipReason(path, relPath, d)
+}
+
+// defaultSkipReason aplica as listas embutidas e os arquivos de exclusão.
+func (s *Scanner) defaultSkipReason(path, relPath string, d fs.DirEntry) (string, bool) {
 	name := d.Name()
 
 	// Pular diretórios irrelevantes
@@ -94,21 +298,20 @@ func (s *Scanner) shouldSkipPath(path string, d fs.DirEntry) bool {
 		}
 
 		for _, skipDir := range skipDirs {
-			if name == skipDir || (strings.HasPrefix(name, ".") && name != ".") {
-				return true
+			if name == skipDir {
+				return "built-in: " + skipDir, true
 			}
 		}
-		return false
-	}
+		if strings.HasPrefix(name, ".") && name != "." {
+			return "built-in: hidden directory", true
+		}
 
-	// Pular arquivos irrelevantes
-	if !strings.HasSuffix(path, ".go") {
-		return true
+		return s.ignoreReason(relPath, true)
 	}
 
 	// Pular testes se não configurado para incluir
 	if !s.config.IncludeTests && strings.HasSuffix(path, "_test.go") {
-		return true
+		return "built-in: test files disabled", true
 	}
 
 	// Pular arquivos específicos
@@ -119,11 +322,32 @@ func (s *Scanner) shouldSkipPath(path string, d fs.DirEntry) bool {
 	baseName := filepath.Base(path)
 	for _, skipFile := range skipFiles {
 		if baseName == skipFile {
-			return true
+			return "built-in: " + skipFile, true
 		}
 	}
 
-	return false
+	return s.ignoreReason(relPath, false)
+}
+
+// ignoreReason aplica as regras de .gitignore/.contextignore carregadas.
+func (s *Scanner) ignoreReason(relPath string, isDir bool) (string, bool) {
+	if s.ignore == nil {
+		return "", false
+	}
+	if rule, ignored := s.ignore.match(relPath, isDir); ignored {
+		return rule.String(), true
+	}
+	return "", false
+}
+
+// relPath retorna o caminho relativo à raiz do escaneamento, separado por "/".
+// A própria raiz resulta em "".
+func (s *Scanner) relPath(path string) string {
+	rel, err := filepath.Rel(s.root, path)
+	if err != nil || rel == "." {
+		return ""
+	}
+	return filepath.ToSlash(rel)
 }
 
 func (s *Scanner) parseGoFile(filePath string) (*GoFile, error) {
@@ -132,9 +356,6 @@ func (s *Scanner) parseGoFile(filePath string) (*GoFile, error) {
 		return nil, err
 	}
 
-	// Obter tamanho do arquivo
-	stat, _ := os.Stat(filePath)
-
 	node, err := parser.ParseFile(s.fset, filePath, content, parser.ParseComments)
 	if err != nil {
 		return nil, err
@@ -146,7 +367,7 @@ func (s *Scanner) parseGoFile(filePath string) (*GoFile, error) {
 		Package: node.Name.Name,
 		Content: string(content),
 		AST:     node,
-		Size:    stat.Size(),
+		Size:    int64(len(content)), // Sem um segundo Stat: o arquivo pode sumir entre as leituras
 		LOC:     s.countLines(string(content)),
 	}
 
@@ -157,7 +378,9 @@ func (s *Scanner) parseGoFile(filePath string) (*GoFile, error) {
 	}
 
 	// Limpar conteúdo para IA
-	goFile.CleanContent = s.cleanContentForAI(string(content))
+	goFile.CleanContent = s.cleanContentForAI(string(content), node)
+	goFile.API = s.apiSurface(node)
+	goFile.Skeleton = s.skeleton(node)
 
 	return goFile, nil
 }
@@ -166,254 +389,100 @@ func (s *Scanner) countLines(content string) int {
 	return len(strings.Split(content, "\n"))
 }
 
-func (s *Scanner) cleanContentForAI(content string) string {
-	if !s.config.RemoveComments && !s.config.MinifyOutput {
-		return content
+// resolveDependencies liga cada arquivo aos arquivos dos pacotes locais que
+// ele importa. Os import paths são resolvidos pelos módulos conhecidos e cada
+// pacote é carregado com go/build, respeitando build tags e GOOS/GOARCH.
+func (s *Scanner) resolveDependencies(files []*GoFile, projectDir string) {
+	// Sem go.mod nem go.work, o nome da pasta faz o papel do módulo
+	if _, ok := s.modules.importPath(projectDir); !ok {
+		s.modules.register(filepath.Base(projectDir), projectDir)
 	}
 
-	lines := strings.Split(content, "\n")
-	var cleanLines []string
+	fileMap := make(map[string]*GoFile, len(files))
+	for _, file := range files {
+		abs, err := filepath.Abs(file.Path)
+		if err != nil {
+			abs = file.Path
+		}
+		fileMap[abs] = file
 
-	inBlockComment := false
-	inStringLiteral := false
-	emptyLineCount := 0
+		file.ImportPath, _ = s.modules.importPath(filepath.Dir(file.Path))
+	}
 
-	for _, line := range lines {
-		originalLine := line
-		trimmed := strings.TrimSpace(line)
+	ctxt := s.buildContext()
+	packages := make(map[string][]string) // Diretório → arquivos do pacote
 
-		// Pular linhas vazias excessivas
-		if trimmed == "" {
-			if s.config.MinifyOutput {
-				emptyLineCount++
-				if emptyLineCount <= 1 { // Permitir apenas uma linha vazia consecutiva
-					cleanLines = append(cleanLines, "")
-				}
-				continue
-			} else {
-				cleanLines = append(cleanLines, line)
-				continue
-			}
-		}
-		emptyLineCount = 0
+	for _, file := range files {
+		seen := make(map[string]bool)
+		file.Dependencies = nil
+		file.LocalImports = nil
 
-		// Detectar comentários de bloco
-		if strings.Contains(line, "/*") && !inStringLiteral {
-			inBlockComment = true
-			if s.config.RemoveComments && !s.isImportantComment(line) {
+		for _, imp := range file.Imports {
+			dir, ok := s.modules.dir(imp)
+			if !ok {
 				continue
 			}
-		}
+			file.LocalImports = append(file.LocalImports, imp)
 
-		if inBlockComment {
-			if strings.Contains(line, "*/") {
-				inBlockComment = false
+			pkgFiles, loaded := packages[dir]
+			if !loaded {
+				pkgFiles = s.packageFiles(ctxt, dir)
+				packages[dir] = pkgFiles
 			}
-			if s.config.RemoveComments && !s.isImportantComment(line) {
-				continue
-			}
-		}
 
-		// Processar comentários de linha
-		if strings.Contains(line, "//") && !inStringLiteral {
-			if s.config.RemoveComments {
-				line = s.removeLineComments(line)
-				if strings.TrimSpace(line) == "" {
+			for _, path := range pkgFiles {
+				dep, exists := fileMap[path]
+				if !exists || dep == file || seen[dep.Path] {
 					continue
 				}
+				seen[dep.Path] = true
+				file.Dependencies = append(file.Dependencies, dep.Path)
 			}
 		}
 
-		// Otimizar espaçamento se minificando
-		if s.config.MinifyOutput {
-			line = s.optimizeWhitespaceForAI(originalLine)
-		}
-
-		cleanLines = append(cleanLines, line)
-	}
-
-	// Remover linhas vazias no final
-	for len(cleanLines) > 0 && strings.TrimSpace(cleanLines[len(cleanLines)-1]) == "" {
-		cleanLines = cleanLines[:len(cleanLines)-1]
-	}
-
-	return strings.Join(cleanLines, "\n")
-}
-
-func (s *Scanner) removeLineComments(line string) string {
-	// Procurar por // que não estão dentro de strings
-	inString := false
-	inRawString := false
-	escaped := false
-
-	for i, char := range line {
-		if escaped {
-			escaped = false
-			continue
-		}
-
-		if char == '\\' && inString && !inRawString {
-			escaped = true
-			continue
-		}
-
-		if char == '`' {
-			inRawString = !inRawString
-			continue
-		}
-
-		if char == '"' && !inRawString {
-			inString = !inString
-			continue
-		}
-
-		if !inString && !inRawString && i < len(line)-1 {
-			if line[i] == '/' && line[i+1] == '/' {
-				comment := line[i:]
-				if s.isImportantComment(comment) {
-					return line // Manter comentário importante
-				}
-				// Remover comentário, mantendo código antes dele
-				codePart := strings.TrimRight(line[:i], " \t")
-				return codePart
-			}
-		}
-	}
-
-	return line
-}
-
-func (s *Scanner) isImportantComment(comment string) bool {
-	lower := strings.ToLower(comment)
-
-	// Comentários importantes para manter
-	importantKeywords := []string{
-		"//go:", "//go:generate", "//go:build", "//go:embed",
-		"+build", "package ", "copyright", "license", "author",
-		"todo", "fixme", "note", "warning", "deprecated",
-		"bug", "hack", "important", "security", "performance",
-		"api", "public", "exported", "interface",
-	}
-
-	for _, keyword := range importantKeywords {
-		if strings.Contains(lower, keyword) {
-			return true
-		}
-	}
-
-	// Comentários de documentação (antes de declarações)
-	if strings.HasPrefix(lower, "// ") && (strings.Contains(lower, "func ") ||
-		strings.Contains(lower, "type ") ||
-		strings.Contains(lower, "var ") ||
-		strings.Contains(lower, "const ")) {
-		return true
+		sort.Strings(file.Dependencies)
 	}
-
-	return false
 }
 
-func (s *Scanner) optimizeWhitespaceForAI(line string) string {
-	if strings.TrimSpace(line) == "" {
-		return ""
+// buildContext configura o go/build com o alvo do ScanConfig.
+func (s *Scanner) buildContext() build.Context {
+	ctxt := build.Default
+	if s.config.GOOS != "" {
+		ctxt.GOOS = s.config.GOOS
 	}
-
-	// Preservar indentação básica mas otimizar
-	leadingSpaces := len(line) - len(strings.TrimLeft(line, " \t"))
-	content := strings.TrimSpace(line)
-
-	// Reduzir indentação excessiva
-	indent := leadingSpaces
-	if indent > 16 { // Máximo de 16 espaços de indentação
-		indent = 16
+	if s.config.GOARCH != "" {
+		ctxt.GOARCH = s.config.GOARCH
 	}
-
-	// Usar tabs para economizar tokens
-	tabCount := indent / 4
-	spaceCount := indent % 4
-
-	return strings.Repeat("\t", tabCount) + strings.Repeat(" ", spaceCount) + content
-}
-
-func (s *Scanner) resolveDependencies(files []*GoFile, projectDir string) {
-	projectModule := s.getProjectModule(projectDir)
-	fileMap := make(map[string]*GoFile)
-	packageFiles := make(map[string][]*GoFile)
-
-	// Criar mapas
-	for _, file := range files {
-		fileMap[file.Path] = file
-		packageFiles[file.Package] = append(packageFiles[file.Package], file)
-	}
-
-	// Resolver dependências
-	for _, file := range files {
-		dependencies := make(map[string]bool)
-
-		for _, imp := range file.Imports {
-			// Dependências locais do projeto
-			if strings.HasPrefix(imp, projectModule) {
-				relatedFiles := s.findRelatedFiles(imp, projectModule, projectDir, fileMap)
-				for _, related := range relatedFiles {
-					if related != file.Path { // Não incluir o próprio arquivo
-						dependencies[related] = true
-					}
-				}
-			}
-		}
-
-		// Converter map para slice ordenado
-		for dep := range dependencies {
-			file.Dependencies = append(file.Dependencies, dep)
-		}
+	if s.config.GOOS != "" || s.config.GOARCH != "" {
+		// Como o comando go: cgo só fica ativo por padrão no alvo nativo
+		ctxt.CgoEnabled = build.Default.CgoEnabled && ctxt.GOOS == build.Default.GOOS && ctxt.GOARCH == build.Default.GOARCH
 	}
+	ctxt.BuildTags = s.config.BuildTags
+	return ctxt
 }
 
-func (s *Scanner) getProjectModule(projectDir string) string {
-	modPath := filepath.Join(projectDir, "go.mod")
-	if content, err := os.ReadFile(modPath); err == nil {
-		// Usar regex para extrair módulo de forma mais robusta
-		re := regexp.MustCompile(`module\s+([^\s\n]+)`)
-		matches := re.FindStringSubmatch(string(content))
-		if len(matches) > 1 {
-			return strings.TrimSpace(matches[1])
+// packageFiles retorna os caminhos absolutos dos arquivos que compõem o
+// pacote em dir para o alvo configurado. Subdiretórios são outros pacotes e
+// nunca entram; testes do próprio pacote entram apenas com IncludeTests.
+func (s *Scanner) packageFiles(ctxt build.Context, dir string) []string {
+	pkg, err := ctxt.ImportDir(dir, 0)
+	if err != nil {
+		// Pacotes com vários nomes ou com arquivos ilegíveis (listados em
+		// Diagnostics) ainda têm os arquivos válidos em GoFiles
+		_, multiple := err.(*build.MultiplePackageError)
+		if pkg == nil || !multiple && len(pkg.InvalidGoFiles) == 0 {
+			return nil
 		}
 	}
-	return filepath.Base(projectDir)
-}
-
-func (s *Scanner) findRelatedFiles(importPath, projectModule, projectDir string, fileMap map[string]*GoFile) []string {
-	var relatedFiles []string
 
-	// Remover o módulo do projeto do import path
-	relPath := strings.TrimPrefix(importPath, projectModule)
-	if strings.HasPrefix(relPath, "/") {
-		relPath = relPath[1:]
+	names := append(append([]string{}, pkg.GoFiles...), pkg.CgoFiles...)
+	if s.config.IncludeTests {
+		names = append(names, pkg.TestGoFiles...)
 	}
 
-	fullPath := filepath.Join(projectDir, relPath)
-
-	// Verificar se é um diretório
-	if stat, err := os.Stat(fullPath); err == nil && stat.IsDir() {
-		// Procurar arquivos Go no diretório
-		filepath.WalkDir(fullPath, func(path string, d fs.DirEntry, err error) error {
-			if err != nil || d.IsDir() {
-				return nil
-			}
-
-			if strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
-				if _, exists := fileMap[path]; exists {
-					relatedFiles = append(relatedFiles, path)
-				}
-			}
-			return nil
-		})
-	} else {
-		// Pode ser um arquivo específico
-		goFile := fullPath + ".go"
-		if _, exists := fileMap[goFile]; exists {
-			relatedFiles = append(relatedFiles, goFile)
-		}
+	paths := make([]string, 0, len(names))
+	for _, name := range names {
+		paths = append(paths, filepath.Join(dir, name))
 	}
-
-	return relatedFiles
+	return paths
 }
@@ -0,0 +1,51 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"go-context-generator/internal/testfixture"
+)
+
+// BenchmarkScanDirectory compara o escaneamento sequencial com o paralelo:
+//
+//	go test -run '^$' -bench ScanDirectory ./internal/analyzer/
+func BenchmarkScanDirectory(b *testing.B) {
+	dir := b.TempDir()
+	testfixture.Write(b, dir, 40, 10)
+
+	for _, workers := range benchWorkers() {
+		for _, slice := range []bool{false, true} {
+			b.Run(fmt.Sprintf("workers=%d/slice=%t", workers, slice), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					// Um scanner novo a cada volta: ele reaproveita os arquivos
+					// que não mudaram entre escaneamentos
+					scanner := NewScanner(ScanConfig{
+						RemoveComments:    true,
+						KeepExportedDocs:  true,
+						MinifyOutput:      true,
+						SliceDependencies: slice,
+						Workers:           workers,
+					})
+					files, err := scanner.ScanDirectory(context.Background(), dir)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if len(files) != 401 {
+						b.Fatalf("%d arquivos, want 401", len(files))
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchWorkers retorna 1 e GOMAXPROCS, sem repetir em máquinas de um núcleo.
+func benchWorkers() []int {
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		return []int{1, n}
+	}
+	return []int{1}
+}
@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/config"
+	"go-context-generator/internal/generator"
+	"go-context-generator/internal/prompt"
+	"go-context-generator/internal/tokenizer"
+	"go-context-generator/internal/watch"
+)
+
+// Códigos de saída da CLI
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// Run executa a CLI com os argumentos informados (sem o nome do programa) e
+// retorna o código de saída do processo.
+func Run(args []string) int {
+	return run(args, os.Stdout, os.Stderr)
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "generate":
+		return runGenerate(args[1:], stderr)
+	case "help", "-h", "-help", "--help":
+		printUsage(stdout)
+		return exitOK
+	default:
+		fmt.Fprintf(stderr, "comando desconhecido: %q\n\n", args[0])
+		printUsage(stderr)
+		return exitUsage
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Uso: go-context-generator <comando> [opções]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Comandos:")
+	fmt.Fprintln(w, "  generate   Escaneia o projeto e gera os arquivos de contexto")
+	fmt.Fprintln(w, "  help       Mostra esta ajuda")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Sem argumentos, abre a interface gráfica.")
+	fmt.Fprintln(w, "Use \"go-context-generator generate -h\" para ver as opções.")
+}
+
+func runGenerate(args []string, stderr io.Writer) int {
+	scanConfig := analyzer.ScanConfig{
+		RemoveComments:   true,
+		KeepExportedDocs: true,
+		MinifyOutput:     true,
+		RespectGitignore: true,
+	}
+	genConfig := generator.Config{}
+	quiet := false
+	watchMode := false
+	mode := string(generator.ModePerFile)
+	format := string(generator.FormatText)
+	naming := string(generator.NamingFlat)
+	tokenizerName := tokenizer.EstimatorName
+	templateName := ""
+	promptName := ""
+	graphFormats := ""
+	graphLevel := string(generator.GraphFiles)
+	promptPosition := string(generator.PromptTop)
+	mainView := string(generator.ViewFull)
+	depsView := string(generator.ViewFull)
+
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&genConfig.SourceDir, "src", ".", "pasta raiz do projeto Go")
+	fs.StringVar(&genConfig.OutputDir, "out", "go-contexts", "pasta onde salvar os arquivos de contexto")
+	fs.StringVar(&mode, "mode", mode, "modo de saída: files (um contexto por arquivo), packages (um contexto por pacote) ou bundle (documento único)")
+	fs.StringVar(&format, "format", format, "formato dos documentos: text (.txt), markdown (.md) ou xml (.xml, com tags e CDATA)")
+	fs.StringVar(&naming, "naming", naming, "nomes dos contextos: flat (achatados, com hash do caminho) ou mirror (estrutura de pastas da origem)")
+	fs.StringVar(&templateName, "template", "", "template dos documentos: "+strings.Join(generator.BuiltinTemplates(), ", ")+" ou caminho de um arquivo text/template (blocos ausentes seguem -format)")
+	fs.StringVar(&promptName, "prompt", "", "prompt de tarefa inserido em cada contexto: "+strings.Join(prompt.Names(config.PromptDir()), ", ")+" (presets de "+config.PromptDir()+") ou caminho de um arquivo")
+	fs.StringVar(&promptPosition, "prompt-position", promptPosition, "posição do prompt nos contextos: top (início) ou bottom (fim)")
+	fs.BoolVar(&genConfig.OmitTimestamps, "no-timestamps", false, "omitir a data da geração dos documentos (saída idêntica para a mesma entrada); SOURCE_DATE_EPOCH fixa a data")
+	fs.BoolVar(&genConfig.ExportJSON, "json", false, "gravar também project.json e files.jsonl (exportação estruturada)")
+	fs.StringVar(&graphFormats, "graph", "", "gravar também o grafo de dependências: dot, mermaid e/ou json, separados por vírgula (ciclos destacados)")
+	fs.StringVar(&graphLevel, "graph-level", graphLevel, "nós do grafo: files (arquivos) ou packages (arquivos agrupados por pacote)")
+	fs.BoolVar(&scanConfig.IncludeTests, "include-tests", false, "processar arquivos *_test.go")
+	fs.BoolVar(&scanConfig.IncludeUnparseable, "include-unparseable", false, "incluir arquivos com erro de sintaxe como código bruto, marcados como unparseable")
+	fs.BoolVar(&scanConfig.RemoveComments, "remove-comments", true, "remover comentários desnecessários")
+	fs.Var(negatedBool{&scanConfig.RemoveComments}, "keep-comments", "manter todos os comentários (o mesmo que -remove-comments=false)")
+	fs.BoolVar(&scanConfig.KeepExportedDocs, "keep-docs", true, "ao remover comentários, manter a documentação do pacote e dos identificadores exportados")
+	fs.BoolVar(&scanConfig.MinifyOutput, "minify", true, "otimizar espaços em branco para IA")
+	fs.Var(negatedBool{&scanConfig.MinifyOutput}, "no-minify", "não otimizar espaços em branco (o mesmo que -minify=false)")
+	fs.BoolVar(&scanConfig.RespectGitignore, "gitignore", true, "respeitar os arquivos .gitignore do projeto (.contextignore é sempre lido)")
+	fs.Var(patternFlag{&scanConfig.PathPatterns, "+"}, "include", "glob doublestar a incluir (repetível; o último padrão que casa vence)")
+	fs.Var(patternFlag{&scanConfig.PathPatterns, "-"}, "exclude", "glob doublestar a excluir (repetível; o último padrão que casa vence)")
+	fs.Var(tagsFlag{&scanConfig.BuildTags}, "tags", "build tags separadas por vírgula usadas na resolução de dependências")
+	fs.StringVar(&scanConfig.GOOS, "goos", "", "GOOS alvo da resolução de dependências (padrão: o do ambiente)")
+	fs.StringVar(&scanConfig.GOARCH, "goarch", "", "GOARCH alvo da resolução de dependências (padrão: o do ambiente)")
+	fs.StringVar(&tokenizerName, "tokenizer", tokenizerName, "contador de tokens: estimate, cl100k_base, o200k_base (vocabulário em "+config.TokenizerDir()+") ou caminho de um .tiktoken")
+	genConfig.DependencyDepth = 1
+	fs.Var(depthFlag{&genConfig.DependencyDepth}, "depth", "níveis de dependências locais em cada contexto: 1 (apenas diretas), N ou all (fecho transitivo)")
+	fs.StringVar(&mainView, "main-view", mainView, "código principal: full (completo) ou skeleton (assinaturas, sem corpos)")
+	fs.StringVar(&depsView, "deps-view", depsView, "código das dependências: full (completo) ou skeleton (assinaturas, sem corpos)")
+	fs.BoolVar(&scanConfig.SliceDependencies, "slice", false, "incluir das dependências apenas as declarações referenciadas (checagem de tipos com go/types)")
+	fs.IntVar(&genConfig.MaxTokensPerFile, "max-tokens", 0, "orçamento de tokens por arquivo de contexto; 0 desativa")
+	fs.BoolVar(&genConfig.Force, "force", false, "reescrever todos os contextos, mesmo os que não mudaram desde a última geração")
+	fs.BoolVar(&genConfig.Prune, "prune", false, "apagar da pasta de saída todo *_CONTEXT.* que esta geração não produziu (recusa pastas que não parecem uma geração anterior)")
+	fs.IntVar(&scanConfig.Workers, "workers", 0, "goroutines para interpretar arquivos e gerar contextos; 0 usa GOMAXPROCS")
+	fs.BoolVar(&watchMode, "watch", false, "depois de gerar, observar a origem e regenerar os contextos afetados a cada alteração")
+	fs.BoolVar(&quiet, "quiet", false, "não mostrar o progresso no stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Uso: go-context-generator generate [opções]")
+		fmt.Fprintln(stderr, "")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "argumentos inesperados: %v\n", fs.Args())
+		fs.Usage()
+		return exitUsage
+	}
+
+	if stat, err := os.Stat(genConfig.SourceDir); err != nil || !stat.IsDir() {
+		fmt.Fprintf(stderr, "❌ pasta de origem inválida: %s\n", genConfig.SourceDir)
+		return exitUsage
+	}
+
+	outputMode, err := generator.ParseOutputMode(mode)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	genConfig.Mode = outputMode
+
+	outputFormat, err := generator.ParseFormat(format)
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	genConfig.Format = outputFormat
+
+	if genConfig.Naming, err = generator.ParseNamingScheme(naming); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	if genConfig.GraphFormats, err = generator.ParseGraphFormats(graphFormats); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	if genConfig.GraphLevel, err = generator.ParseGraphLevel(graphLevel); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	if genConfig.Timestamp, _, err = generator.SourceDateEpoch(); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	if genConfig.MainView, err = generator.ParseCodeView(mainView); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	if genConfig.DependencyView, err = generator.ParseCodeView(depsView); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	if err := genConfig.CheckMode(); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	tok, err := tokenizer.New(tokenizerName, config.TokenizerDir())
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+	genConfig.Tokenizer = tok
+
+	if templateName != "" {
+		if genConfig.Template, err = generator.LoadTemplate(templateName); err != nil {
+			fmt.Fprintf(stderr, "❌ %v\n", err)
+			return exitUsage
+		}
+	}
+
+	if promptName != "" {
+		// Sem a cópia na pasta, os presets embutidos continuam disponíveis
+		_ = prompt.Install(config.PromptDir())
+		if genConfig.Prompt, err = prompt.Load(promptName, config.PromptDir()); err != nil {
+			fmt.Fprintf(stderr, "❌ %v\n", err)
+			return exitUsage
+		}
+	}
+	if genConfig.PromptPosition, err = generator.ParsePromptPosition(promptPosition); err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return exitUsage
+	}
+
+	genConfig.RemoveComments = scanConfig.RemoveComments
+	genConfig.MinifyOutput = scanConfig.MinifyOutput
+	genConfig.SliceDependencies = scanConfig.SliceDependencies
+	genConfig.Workers = scanConfig.Workers
+
+	if !quiet {
+		fmt.Fprintf(stderr, "🔍 Escaneando %s...\n", genConfig.SourceDir)
+	}
+
+	// Ctrl+C cancela a geração sem deixar arquivos pela metade
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	scanner := analyzer.NewScanner(scanConfig)
+	code := generate(ctx, scanner, genConfig, nil, quiet, stderr)
+	if !watchMode || ctx.Err() != nil {
+		return code
+	}
+
+	// Modo observação: cada lote regenera apenas os contextos afetados pelas
+	// alterações desde a última geração concluída, e o scanner reaproveita
+	// os arquivos inalterados. Depois de uma falha, a próxima geração é
+	// completa
+	fmt.Fprintf(stderr, "👀 Observando %s; Ctrl+C para sair\n", genConfig.SourceDir)
+	incremental := code == exitOK
+	var pending []string
+	err = watch.Run(ctx, watch.Config{
+		Root: genConfig.SourceDir,
+		Skip: scanner.ShouldSkip,
+		OnChange: func(paths []string) {
+			if len(paths) == 1 {
+				fmt.Fprintf(stderr, "🔄 Alterado: %s\n", paths[0])
+			} else {
+				fmt.Fprintf(stderr, "🔄 %d caminhos alterados\n", len(paths))
+			}
+			pending = append(pending, paths...)
+			changed := pending
+			if !incremental {
+				changed = nil
+			}
+			incremental = generate(ctx, scanner, genConfig, changed, quiet, stderr) == exitOK
+			if incremental {
+				pending = nil
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ Erro ao observar %s: %v\n", genConfig.SourceDir, err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// generate escaneia a origem e grava os contextos, relatando o resultado no
+// stderr; changed restringe a geração aos contextos afetados (ver
+// Generator.SetChangedPaths). Retorna o código de saída correspondente.
+func generate(ctx context.Context, scanner *analyzer.Scanner, genConfig generator.Config, changed []string, quiet bool, stderr io.Writer) int {
+	files, err := scanner.ScanDirectory(ctx, genConfig.SourceDir)
+	if errors.Is(err, context.Canceled) {
+		fmt.Fprintln(stderr, "⚠️ Escaneamento cancelado")
+		return exitFailure
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ Erro ao escanear arquivos: %v\n", err)
+		return exitFailure
+	}
+
+	// Problemas são mostrados mesmo com -quiet
+	for _, problem := range scanner.Diagnostics() {
+		action := "ignorado"
+		if problem.Included {
+			action = "incluído como código bruto"
+		}
+		fmt.Fprintf(stderr, "⚠️ %s: %s (%s)\n", problem.Position(), problem.Reason, action)
+	}
+
+	if len(files) == 0 {
+		fmt.Fprintln(stderr, "⚠️ Nenhum arquivo de código fonte encontrado na pasta de origem")
+		return exitFailure
+	}
+
+	gen := generator.NewGenerator(genConfig)
+	gen.SetSkippedPaths(scanner.SkippedPaths())
+	gen.SetDiagnostics(scanner.Diagnostics())
+	gen.SetChangedPaths(changed)
+	if !quiet {
+		fmt.Fprintf(stderr, "🔄 Encontrados %d arquivos. Gerando contextos em %s...\n", len(files), genConfig.OutputDir)
+		gen.SetProgressCallback(func(current, total int) {
+			fmt.Fprintf(stderr, "\r⚡ Processando... %d/%d arquivos", current, total)
+			if current == total {
+				fmt.Fprintln(stderr)
+			}
+		})
+	}
+
+	if err := gen.GenerateContextFiles(ctx, files); err != nil {
+		if errors.Is(err, context.Canceled) {
+			if !quiet {
+				fmt.Fprintln(stderr) // Terminar a linha de progresso
+			}
+			fmt.Fprintln(stderr, "⚠️ Geração cancelada; a pasta de saída não foi alterada")
+			return exitFailure
+		}
+		fmt.Fprintf(stderr, "❌ Erro na geração: %v\n", err)
+		return exitFailure
+	}
+
+	if !quiet {
+		if reused := gen.Reused(); reused > 0 {
+			fmt.Fprintf(stderr, "✅ Concluído! %d documentos gravados (%d sem alterações)\n", gen.Written(), reused)
+		} else {
+			fmt.Fprintf(stderr, "✅ Concluído! %d documentos gravados\n", gen.Written())
+		}
+	}
+
+	return exitOK
+}
+
+// negatedBool é uma flag booleana que grava o valor invertido no destino,
+// permitindo atalhos como -no-minify para -minify=false.
+type negatedBool struct {
+	target *bool
+}
+
+func (n negatedBool) String() string {
+	if n.target == nil {
+		return "false"
+	}
+	return strconv.FormatBool(!*n.target)
+}
+
+func (n negatedBool) Set(value string) error {
+	v, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*n.target = !v
+	return nil
+}
+
+func (n negatedBool) IsBoolFlag() bool { return true }
+
+// patternFlag acumula -include/-exclude na mesma lista ordenada, prefixando
+// cada glob com "+" ou "-".
+type patternFlag struct {
+	target *[]string
+	prefix string
+}
+
+func (p patternFlag) String() string {
+	return ""
+}
+
+func (p patternFlag) Set(value string) error {
+	*p.target = append(*p.target, p.prefix+value)
+	return nil
+}
+
+// tagsFlag aceita a mesma sintaxe de "go build -tags": nomes separados por vírgula.
+type tagsFlag struct {
+	target *[]string
+}
+
+func (t tagsFlag) String() string {
+	if t.target == nil {
+		return ""
+	}
+	return strings.Join(*t.target, ",")
+}
+
+func (t tagsFlag) Set(value string) error {
+	*t.target = nil
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			*t.target = append(*t.target, tag)
+		}
+	}
+	return nil
+}
+
+// depthFlag aceita um número de níveis ou "all" (generator.DepthAll).
+type depthFlag struct {
+	target *int
+}
+
+func (d depthFlag) String() string {
+	if d.target == nil {
+		return "1"
+	}
+	if *d.target == generator.DepthAll {
+		return "all"
+	}
+	return strconv.Itoa(*d.target)
+}
+
+func (d depthFlag) Set(value string) error {
+	if strings.EqualFold(value, "all") {
+		*d.target = generator.DepthAll
+		return nil
+	}
+	depth, err := strconv.Atoi(value)
+	if err != nil || depth < 1 {
+		return fmt.Errorf("profundidade inválida: %q (use um número a partir de 1 ou all)", value)
+	}
+	*d.target = depth
+	return nil
+}
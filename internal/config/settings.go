@@ -7,18 +7,55 @@ import (
 )
 
 type Settings struct {
-	RemoveComments bool   `json:"remove_comments"`
-	IncludeTests   bool   `json:"include_tests"`
-	MinifyOutput   bool   `json:"minify_output"`
-	LastSrcPath    string `json:"last_src_path"`
-	LastDestPath   string `json:"last_dest_path"`
+	RemoveComments     bool     `json:"remove_comments"`
+	KeepExportedDocs   bool     `json:"keep_exported_docs"`
+	IncludeTests       bool     `json:"include_tests"`
+	IncludeUnparseable bool     `json:"include_unparseable"` // Incluir arquivos com erro de sintaxe como código bruto
+	MinifyOutput       bool     `json:"minify_output"`
+	RespectGitignore   bool     `json:"respect_gitignore"`
+	OutputMode         string   `json:"output_mode"`   // "files", "bundle" ou "packages"
+	OutputFormat       string   `json:"output_format"` // "text", "markdown" ou "xml"
+	OutputNaming       string   `json:"output_naming"` // "flat" ou "mirror"
+	ExportJSON         bool     `json:"export_json"`
+	GraphFormats       []string `json:"graph_formats"`       // "dot", "mermaid" e/ou "json"; vazio = sem grafo
+	GraphLevel         string   `json:"graph_level"`         // "files" ou "packages"
+	OmitTimestamps     bool     `json:"omit_timestamps"`     // Documentos sem a data da geração
+	Template           string   `json:"template"`            // Template embutido ou caminho de um text/template; vazio = formato
+	Prompt             string   `json:"prompt"`              // Prompt de tarefa (preset ou caminho); vazio = nenhum
+	PromptPosition     string   `json:"prompt_position"`     // "top" ou "bottom"
+	Tokenizer          string   `json:"tokenizer"`           // "estimate", "cl100k_base", "o200k_base" ou caminho .tiktoken
+	MaxTokensPerFile   int      `json:"max_tokens_per_file"` // 0 = sem limite
+	DependencyDepth    int      `json:"dependency_depth"`    // 1 = apenas diretas, N níveis, -1 = fecho transitivo completo
+	SliceDependencies  bool     `json:"slice_dependencies"`  // Incluir só as declarações referenciadas das dependências
+	MainView           string   `json:"main_view"`           // "full" ou "skeleton" para o código principal
+	DependencyView     string   `json:"dependency_view"`     // "full" ou "skeleton" para as dependências
+	Watch              bool     `json:"watch"`               // Depois de gerar, regenerar a cada alteração na origem
+	PruneOutputs       bool     `json:"prune_outputs"`       // Apagar do destino os *_CONTEXT.* que a geração não produziu
+	Workers            int      `json:"workers"`             // Goroutines de processamento; 0 = GOMAXPROCS
+	PathPatterns       []string `json:"path_patterns"`       // "+glob" inclui, "-glob" exclui; o último que casa vence
+	BuildTags          []string `json:"build_tags"`          // Tags extras na resolução de dependências
+	GOOS               string   `json:"goos"`                // Vazio = sistema atual
+	GOARCH             string   `json:"goarch"`              // Vazio = arquitetura atual
+	LastSrcPath        string   `json:"last_src_path"`
+	LastDestPath       string   `json:"last_dest_path"`
 }
 
 func LoadSettings() *Settings {
 	settings := &Settings{
-		RemoveComments: true,
-		IncludeTests:   false,
-		MinifyOutput:   true,
+		RemoveComments:   true,
+		KeepExportedDocs: true,
+		IncludeTests:     false,
+		MinifyOutput:     true,
+		RespectGitignore: true,
+		OutputMode:       "files",
+		OutputFormat:     "text",
+		OutputNaming:     "flat",
+		PromptPosition:   "top",
+		GraphLevel:       "files",
+		Tokenizer:        "estimate",
+		DependencyDepth:  1,
+		MainView:         "full",
+		DependencyView:   "full",
 	}
 
 	configPath := getConfigPath()
@@ -42,6 +79,21 @@ func (s *Settings) Save() error {
 }
 
 func getConfigPath() string {
+	return filepath.Join(ConfigDir(), "settings.json")
+}
+
+// ConfigDir retorna a pasta de configuração da aplicação.
+func ConfigDir() string {
 	homeDir, _ := os.UserHomeDir()
-	return filepath.Join(homeDir, ".config", "go-context-generator", "settings.json")
+	return filepath.Join(homeDir, ".config", "go-context-generator")
+}
+
+// TokenizerDir retorna a pasta onde os vocabulários .tiktoken são procurados.
+func TokenizerDir() string {
+	return filepath.Join(ConfigDir(), "tokenizers")
+}
+
+// PromptDir retorna a pasta dos prompts de tarefa (.txt) do usuário.
+func PromptDir() string {
+	return filepath.Join(ConfigDir(), "prompts")
 }
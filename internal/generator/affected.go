@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// projectFiles mudam a resolução de dependências ou as regras de exclusão de
+// todo o projeto; alterá-los regenera todos os contextos.
+var projectFiles = map[string]bool{
+	"go.mod":         true,
+	"go.work":        true,
+	".gitignore":     true,
+	".contextignore": true,
+}
+
+// SetChangedPaths restringe a próxima geração aos contextos afetados pelos
+// caminhos alterados (arquivos ou diretórios, como entregues pelo modo
+// observação): os dos pacotes com arquivos alterados e os de quem depende
+// deles, direta ou indiretamente. Os demais contextos da geração anterior
+// são mantidos sem recalcular as entradas. Os caminhos devem cobrir todas
+// as alterações desde a última geração concluída. nil regenera tudo, assim
+// como alterações em go.mod, go.work ou nas regras de exclusão, mudanças na
+// estrutura de pacotes e na configuração, e o uso de um template.
+func (g *Generator) SetChangedPaths(paths []string) {
+	g.changed = paths
+}
+
+// affectedFiles retorna os arquivos, pelo caminho, cujos contextos podem ter
+// mudado com as alterações informadas em SetChangedPaths; nil indica todos.
+func (g *Generator) affectedFiles(files []*analyzer.GoFile) map[string]bool {
+	// Com um template, as estatísticas do projeto podem aparecer em todos os
+	// contextos (ver contextInputs)
+	if g.changed == nil || g.config.Template != nil {
+		return nil
+	}
+
+	var packageDirs, trees []string
+	for _, path := range g.changed {
+		path = filepath.Clean(path)
+		switch {
+		case projectFiles[filepath.Base(path)] || path == filepath.Clean(g.config.SourceDir):
+			return nil
+		case filepath.Ext(path) == ".go":
+			// Arquivo alterado, criado ou removido: o pacote inteiro conta,
+			// já que os contextos dependem dos arquivos do pacote importado
+			packageDirs = append(packageDirs, filepath.Dir(path))
+		default:
+			// Diretório criado, removido ou renomeado
+			trees = append(trees, path+string(filepath.Separator))
+		}
+	}
+
+	affected := make(map[string]bool)
+	importers := make(map[string][]string)
+	var queue []string
+	for _, file := range files {
+		for _, dep := range file.Dependencies {
+			importers[dep] = append(importers[dep], file.Path)
+		}
+		if changedFile(file.Path, packageDirs, trees) {
+			affected[file.Path] = true
+			queue = append(queue, file.Path)
+		}
+	}
+
+	// Quem importa um arquivo afetado também é afetado, em qualquer nível:
+	// o conjunto cobre todas as profundidades de dependência
+	for len(queue) > 0 {
+		path := queue[0]
+		queue = queue[1:]
+		for _, importer := range importers[path] {
+			if !affected[importer] {
+				affected[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+	return affected
+}
+
+func changedFile(path string, packageDirs, trees []string) bool {
+	dir := filepath.Dir(path)
+	for _, packageDir := range packageDirs {
+		if dir == packageDir {
+			return true
+		}
+	}
+	for _, tree := range trees {
+		if strings.HasPrefix(path, tree) {
+			return true
+		}
+	}
+	return false
+}
+
+// unaffected informa se nenhum dos arquivos foi afetado pelas alterações da
+// geração restrita (ver SetChangedPaths).
+func (g *Generator) unaffected(files ...*analyzer.GoFile) bool {
+	if g.affected == nil {
+		return false
+	}
+	for _, file := range files {
+		if g.affected[file.Path] {
+			return false
+		}
+	}
+	return true
+}
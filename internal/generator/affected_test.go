@@ -0,0 +1,92 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-context-generator/internal/testfixture"
+)
+
+// TestChangedPathsMatchFullGeneration altera um pacote do meio da cadeia de
+// imports e exige que a geração restrita às alterações produza a mesma
+// saída de uma geração completa, sem visitar os pacotes anteriores.
+func TestChangedPathsMatchFullGeneration(t *testing.T) {
+	for _, mode := range []OutputMode{ModePerFile, ModePackages} {
+		t.Run(string(mode), func(t *testing.T) {
+			src := t.TempDir()
+			testfixture.Write(t, src, 8, 2)
+
+			config := Config{SourceDir: src, OutputDir: t.TempDir(), Mode: mode, OmitTimestamps: true}
+			if err := NewGenerator(config).GenerateContextFiles(context.Background(), scanFixture(t, src, 0)); err != nil {
+				t.Fatal(err)
+			}
+
+			changed := filepath.Join(src, "pkg", "p5", "f0.go")
+			f, err := os.OpenFile(changed, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString("\n// Extra é acrescentada pelo teste.\nfunc Extra() int { return 5 }\n")
+			f.Close()
+
+			files := scanFixture(t, src, 0)
+			gen := NewGenerator(config)
+			gen.SetChangedPaths([]string{changed})
+			if err := gen.GenerateContextFiles(context.Background(), files); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, file := range files {
+				rel := filepath.ToSlash(gen.relPath(file.Path))
+				switch filepath.Dir(rel) {
+				case "pkg/p0", "pkg/p1", "pkg/p2", "pkg/p3", "pkg/p4":
+					if gen.affected[file.Path] {
+						t.Errorf("%s não importa p5, mas foi considerado afetado", rel)
+					}
+				default:
+					if !gen.affected[file.Path] {
+						t.Errorf("%s depende de p5, mas não foi considerado afetado", rel)
+					}
+				}
+			}
+			if gen.Reused() == 0 {
+				t.Error("nenhum contexto foi mantido")
+			}
+
+			full := config
+			full.OutputDir = t.TempDir()
+			full.Force = true
+			if err := NewGenerator(full).GenerateContextFiles(context.Background(), files); err != nil {
+				t.Fatal(err)
+			}
+			compareTrees(t, readTree(t, full.OutputDir), readTree(t, config.OutputDir))
+		})
+	}
+}
+
+func TestChangedPathsFallBackToFullGeneration(t *testing.T) {
+	src := t.TempDir()
+	testfixture.Write(t, src, 3, 1)
+	files := scanFixture(t, src, 0)
+
+	for _, paths := range [][]string{
+		nil,
+		{filepath.Join(src, "go.mod")},
+		{filepath.Join(src, "pkg", ".contextignore")},
+		{src},
+	} {
+		gen := NewGenerator(Config{SourceDir: src})
+		gen.SetChangedPaths(paths)
+		if affected := gen.affectedFiles(files); affected != nil {
+			t.Errorf("%v: esperada geração completa, afetados %v", paths, affected)
+		}
+	}
+
+	gen := NewGenerator(Config{SourceDir: src})
+	gen.SetChangedPaths([]string{filepath.Join(src, "pkg")})
+	if affected := gen.affectedFiles(files); len(affected) != len(files) {
+		t.Errorf("diretório pkg alterado: %d afetados, esperados %d", len(affected), len(files))
+	}
+}
@@ -0,0 +1,141 @@
+package generator
+
+import (
+	"context"
+	"go/ast"
+	"path/filepath"
+	"sort"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/parallel"
+)
+
+// Tokens aproximados do cabeçalho de cada bloco de dependência
+const dependencyHeaderTokens = 20
+
+func (g *Generator) countTokens(text string) int {
+	return g.config.Tokenizer.Count(text)
+}
+
+// countFileTokens conta uma única vez os tokens do código limpo de cada arquivo.
+func (g *Generator) countFileTokens(ctx context.Context, files []*analyzer.GoFile) error {
+	counts := make([]int, len(files))
+	err := parallel.ForEach(ctx, len(files), g.config.Workers, func(i int) error {
+		counts[i] = g.countTokens(files[i].CleanContent)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	g.fileTokens = make(map[string]int, len(files))
+	for i, file := range files {
+		g.fileTokens[file.Path] = counts[i]
+	}
+	return nil
+}
+
+// trimDependencies descarta dependências até economizar pelo menos excess
+// tokens: primeiro as mais distantes do arquivo principal e, no mesmo nível,
+// da menos para a mais relevante para quem a importa. Retorna as mantidas (na
+// ordem original) e as descartadas (na ordem em que foram removidas).
+func (g *Generator) trimDependencies(view *dependencyView, deps []dependencyChain[*analyzer.GoFile], excess int) (kept, omitted []dependencyChain[*analyzer.GoFile]) {
+	type candidate struct {
+		chain     dependencyChain[*analyzer.GoFile]
+		relevance int
+	}
+
+	candidates := make([]candidate, len(deps))
+	for i, chain := range deps {
+		candidates[i] = candidate{chain, dependencyRelevance(chain[len(chain)-2], chain.last())}
+	}
+
+	// Mais profundas primeiro; depois menos relevantes; em empate, as maiores saem antes
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.chain.depth() != b.chain.depth() {
+			return a.chain.depth() > b.chain.depth()
+		}
+		if a.relevance != b.relevance {
+			return a.relevance < b.relevance
+		}
+		return view.tokens(a.chain.last()) > view.tokens(b.chain.last())
+	})
+
+	dropped := make(map[string]bool)
+	for _, c := range candidates {
+		if excess <= 0 {
+			break
+		}
+		dep := c.chain.last()
+		dropped[dep.Path] = true
+		omitted = append(omitted, c.chain)
+		excess -= view.tokens(dep) + dependencyHeaderTokens
+	}
+
+	for _, chain := range deps {
+		if !dropped[chain.last().Path] {
+			kept = append(kept, chain)
+		}
+	}
+
+	return kept, omitted
+}
+
+// dependencyRelevance mede quanto o arquivo usa as declarações da dependência:
+// referências qualificadas (pkg.Nome) valem mais que chamadas de métodos com
+// o mesmo nome de um método declarado nela.
+func dependencyRelevance(file, dep *analyzer.GoFile) int {
+	if file.AST == nil || dep.AST == nil {
+		return 0
+	}
+
+	topLevel := make(map[string]bool)
+	methods := make(map[string]bool)
+	for _, decl := range dep.AST.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv != nil {
+				methods[decl.Name.Name] = true
+			} else {
+				topLevel[decl.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					topLevel[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						topLevel[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	// Nomes pelos quais o pacote da dependência pode ser referenciado
+	pkgNames := map[string]bool{dep.Package: true}
+	depDir := filepath.Base(filepath.Dir(dep.Path))
+	for _, imp := range file.AST.Imports {
+		if imp.Name != nil && filepath.Base(imp.Path.Value[1:len(imp.Path.Value)-1]) == depDir {
+			pkgNames[imp.Name.Name] = true
+		}
+	}
+
+	relevance := 0
+	ast.Inspect(file.AST, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if ident, ok := sel.X.(*ast.Ident); ok && pkgNames[ident.Name] && topLevel[sel.Sel.Name] {
+			relevance += 2
+		} else if methods[sel.Sel.Name] {
+			relevance++
+		}
+		return true
+	})
+
+	return relevance
+}
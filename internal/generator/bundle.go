@@ -0,0 +1,93 @@
+package generator
+
+import (
+	"context"
+	"sort"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// generateBundle escreve um único documento com a visão geral do projeto e o
+// código limpo de cada arquivo exatamente uma vez, com as dependências antes
+// de quem as importa.
+func (g *Generator) generateBundle(ctx context.Context, files []*analyzer.GoFile) error {
+	bundleName := "PROJECT_BUNDLE" + g.renderer.Extension()
+	bundleFile := g.outputPath(bundleName)
+
+	ordered := topologicalOrder(files)
+	total := len(ordered)
+	if g.projectUpToDate(bundleName) {
+		if g.progressCallback != nil {
+			g.progressCallback(total, total)
+		}
+		return nil
+	}
+
+	data := &BundleData{Overview: g.buildOverview(files)}
+	for i, file := range ordered {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if g.progressCallback != nil {
+			g.progressCallback(i, total)
+		}
+		data.Files = append(data.Files, g.mainFileData(file))
+	}
+
+	if err := writeOutput(bundleFile, []byte(g.renderer.Bundle(data))); err != nil {
+		return err
+	}
+
+	if g.progressCallback != nil {
+		g.progressCallback(total, total)
+	}
+
+	return nil
+}
+
+// topologicalOrder ordena os arquivos de forma que cada dependência apareça
+// antes dos arquivos que a usam. Empates são resolvidos pelo caminho, e
+// eventuais ciclos são quebrados na primeira aresta de retorno encontrada.
+func topologicalOrder(files []*analyzer.GoFile) []*analyzer.GoFile {
+	fileMap := make(map[string]*analyzer.GoFile, len(files))
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		fileMap[file.Path] = file
+		paths = append(paths, file.Path)
+	}
+	sort.Strings(paths)
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(files))
+	ordered := make([]*analyzer.GoFile, 0, len(files))
+
+	var visit func(path string)
+	visit = func(path string) {
+		if state[path] != unvisited {
+			return
+		}
+		state[path] = visiting
+
+		file := fileMap[path]
+		deps := append([]string(nil), file.Dependencies...)
+		sort.Strings(deps)
+		for _, dep := range deps {
+			if _, exists := fileMap[dep]; exists {
+				visit(dep)
+			}
+		}
+
+		state[path] = done
+		ordered = append(ordered, file)
+	}
+
+	for _, path := range paths {
+		visit(path)
+	}
+
+	return ordered
+}
@@ -1,6 +1,7 @@
 package generator
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +10,9 @@ import (
 	"time"
 
 	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/parallel"
+	"go-context-generator/internal/prompt"
+	"go-context-generator/internal/tokenizer"
 )
 
 type Config struct {
@@ -16,11 +20,142 @@ type Config struct {
 	SourceDir      string
 	RemoveComments bool
 	MinifyOutput   bool
+	Mode           OutputMode
+	Format         Format
+	ExportJSON     bool // Gravar também project.json e files.jsonl
+
+	// GraphFormats grava também o grafo de dependências nesses formatos (ver
+	// GraphFormat), com arquivos ou pacotes como nós conforme GraphLevel
+	GraphFormats []GraphFormat
+	GraphLevel   GraphLevel
+
+	// Tokenizer usado para contar tokens; nil usa o estimador chars/4
+	Tokenizer tokenizer.Tokenizer
+	// MaxTokensPerFile limita cada arquivo de contexto; 0 desativa o limite
+	MaxTokensPerFile int
+	// DependencyDepth limita os níveis de dependências incluídos: 0 ou 1
+	// apenas imports diretos, N níveis, ou DepthAll para o fecho transitivo
+	DependencyDepth int
+	// SliceDependencies inclui das dependências apenas as declarações
+	// referenciadas; requer arquivos escaneados com o mesmo recorte ativo
+	SliceDependencies bool
+
+	// Visão do código em cada seção: o arquivo principal (ou os arquivos do
+	// pacote/bundle) e as dependências; vazio equivale a ViewFull
+	MainView       CodeView
+	DependencyView CodeView
+
+	// Workers limita as goroutines que geram contextos; 0 usa GOMAXPROCS
+	Workers int
+
+	// Force reescreve todos os documentos, mesmo os que não mudaram desde a
+	// geração anterior (ver ManifestFile)
+	Force bool
+	// Naming define os nomes dos contextos; vazio equivale a NamingFlat
+	Naming NamingScheme
+
+	// OmitTimestamps remove a data da geração dos documentos; Timestamp fixa
+	// essa data (ver SourceDateEpoch). Com os dois vazios, vale o horário da
+	// geração
+	OmitTimestamps bool
+	Timestamp      time.Time
+
+	// Template substitui o layout dos documentos que define; os demais
+	// seguem Format (ver LoadTemplate)
+	Template *Template
+
+	// Prompt, se definido, é inserido em cada contexto (de arquivo, de
+	// pacote ou o bundle) na posição PromptPosition
+	Prompt         *prompt.Prompt
+	PromptPosition PromptPosition
+
+	// Prune apaga da pasta de saída todo *_CONTEXT.* que a geração não
+	// produziu, mesmo fora do manifesto; recusa pastas que não parecem uma
+	// saída anterior
+	Prune bool
+}
+
+// CheckMode recusa opções que o modo de saída não aplica: o bundle traz o
+// código de cada arquivo uma única vez, sem seções de dependências nem
+// orçamento por contexto.
+func (c Config) CheckMode() error {
+	if c.Mode != ModeBundle {
+		return nil
+	}
+	if c.MaxTokensPerFile > 0 {
+		return fmt.Errorf("o modo bundle não aplica o orçamento de tokens por contexto; remova o limite ou use o modo files ou packages")
+	}
+	if c.DependencyView == ViewSkeleton {
+		return fmt.Errorf("o modo bundle não tem seções de dependências; use a visão skeleton do código principal ou o modo files ou packages")
+	}
+	return nil
+}
+
+// OutputMode define como os contextos são distribuídos em arquivos.
+type OutputMode string
+
+const (
+	// ModePerFile gera a visão geral e um *_CONTEXT.txt por arquivo Go (padrão).
+	ModePerFile OutputMode = "files"
+	// ModeBundle gera um único documento com a visão geral e o código de
+	// todos os arquivos uma única vez, em ordem de dependência.
+	ModeBundle OutputMode = "bundle"
+	// ModePackages gera a visão geral e um contexto por pacote (diretório),
+	// nomeado pelo import path.
+	ModePackages OutputMode = "packages"
+)
+
+// ParseOutputMode converte o nome de um modo; vazio resulta em ModePerFile.
+func ParseOutputMode(name string) (OutputMode, error) {
+	switch OutputMode(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ModePerFile:
+		return ModePerFile, nil
+	case ModeBundle:
+		return ModeBundle, nil
+	case ModePackages, "package", "pkg":
+		return ModePackages, nil
+	}
+	return "", fmt.Errorf("modo de saída desconhecido: %q", name)
+}
+
+// CodeView define como o código de uma seção aparece nos documentos.
+type CodeView string
+
+const (
+	// ViewFull mostra o código limpo completo (padrão).
+	ViewFull CodeView = "full"
+	// ViewSkeleton mostra declarações, assinaturas e documentação, sem os
+	// corpos de funções e métodos.
+	ViewSkeleton CodeView = "skeleton"
+)
+
+// ParseCodeView converte o nome de uma visão; vazio resulta em ViewFull.
+func ParseCodeView(name string) (CodeView, error) {
+	switch CodeView(strings.ToLower(strings.TrimSpace(name))) {
+	case "", ViewFull:
+		return ViewFull, nil
+	case ViewSkeleton:
+		return ViewSkeleton, nil
+	}
+	return "", fmt.Errorf("visão de código desconhecida: %q", name)
 }
 
 type Generator struct {
 	config           Config
+	renderer         renderer
 	progressCallback func(current, total int)
+	skippedPaths     []analyzer.SkippedPath
+	diagnostics      []analyzer.Diagnostic
+	fileTokens       map[string]int // Tokens do CleanContent de cada arquivo
+	packages         []PackageData  // Estrutura de pacotes, calculada uma vez por execução
+	manifest         *outputManifest
+	stageDir         string          // Pasta de preparação da execução atual
+	generated        time.Time       // Data registrada nos documentos da execução atual
+	stats            ProjectStats    // Estatísticas do projeto na execução atual
+	module           string          // Caminho do módulo, lido do go.mod uma vez por execução
+	localImports     map[string]bool // Import paths resolvidos pelo scanner como código local
+	changed          []string        // Caminhos alterados (ver SetChangedPaths)
+	affected         map[string]bool // Arquivos afetados por changed; nil = todos
 }
 
 type ProjectStats struct {
@@ -30,22 +165,127 @@ type ProjectStats struct {
 	TotalLOC      int
 	LargestFile   string
 	LargestSize   int64
+	TotalTokens   int    // Soma dos tokens do código limpo de todos os arquivos
+	Tokenizer     string // Nome do tokenizer usado na contagem
 }
 
 func NewGenerator(config Config) *Generator {
-	return &Generator{
-		config: config,
+	if config.Tokenizer == nil {
+		config.Tokenizer = tokenizer.Estimator{}
 	}
+
+	g := &Generator{
+		config:   config,
+		renderer: newRenderer(config.Format),
+	}
+	if config.Template != nil {
+		g.renderer = newTemplateRenderer(config.Template, g.renderer, g.countTokens)
+	}
+	if config.Prompt != nil {
+		g.renderer = &promptRenderer{
+			renderer: g.renderer,
+			prompt:   config.Prompt,
+			bottom:   config.PromptPosition == PromptBottom,
+		}
+	}
+	return g
 }
 
+// SetProgressCallback registra o callback de progresso. Com vários workers
+// ele é chamado a partir de goroutines diferentes, mas nunca em paralelo e
+// sempre com current crescente, terminando em (total, total).
 func (g *Generator) SetProgressCallback(callback func(current, total int)) {
 	g.progressCallback = callback
 }
 
-func (g *Generator) GenerateContextFiles(files []*analyzer.GoFile) error {
-	// Criar diretório de saída
-	if err := os.MkdirAll(g.config.OutputDir, 0755); err != nil {
-		return fmt.Errorf("erro ao criar diretório de saída: %w", err)
+// SetSkippedPaths informa os caminhos excluídos pelo scanner, listados na
+// visão geral do projeto junto com a regra responsável.
+func (g *Generator) SetSkippedPaths(paths []analyzer.SkippedPath) {
+	g.skippedPaths = paths
+}
+
+// SetDiagnostics informa os arquivos que o scanner não conseguiu
+// interpretar, listados como problemas na visão geral do projeto.
+func (g *Generator) SetDiagnostics(diagnostics []analyzer.Diagnostic) {
+	g.diagnostics = diagnostics
+}
+
+// GenerateContextFiles escreve os documentos do modo configurado na pasta de
+// saída. Os documentos são preparados em uma pasta temporária e só depois
+// movidos para a saída, então se ctx for cancelado ou a geração falhar a
+// pasta de saída fica como estava e ctx.Err() é retornado. Contextos cujas
+// entradas não mudaram desde a geração anterior são mantidos, e os que não
+// são mais produzidos são apagados (ver ManifestFile e Prune).
+func (g *Generator) GenerateContextFiles(ctx context.Context, files []*analyzer.GoFile) error {
+	if err := g.prepareOutputDir(); err != nil {
+		return err
+	}
+	defer os.RemoveAll(g.stageDir)
+	g.generated = g.timestamp()
+	g.module = g.readProjectModule()
+	g.localImports = resolvedLocalImports(files)
+
+	if err := g.countFileTokens(ctx, files); err != nil {
+		return err
+	}
+	g.packages = g.packageStructure(files)
+	g.stats = g.calculateProjectStats(files)
+
+	if err := g.loadManifest(ctx, files); err != nil {
+		return err
+	}
+	g.affected = g.affectedFiles(files)
+	g.manifest.project = g.projectInputs(files)
+
+	if err := g.generateOutputs(ctx, files); err != nil {
+		return err
+	}
+
+	if err := g.manifest.conflict; err != nil {
+		return err
+	}
+	if r, ok := g.renderer.(interface{ Err() error }); ok && r.Err() != nil {
+		return r.Err()
+	}
+	if err := g.commitOutputs(); err != nil {
+		return fmt.Errorf("erro ao mover os documentos para a pasta de saída: %w", err)
+	}
+	if g.config.Prune {
+		if err := g.pruneOutputs(); err != nil {
+			return fmt.Errorf("erro ao apagar contextos antigos: %w", err)
+		}
+	}
+	if err := g.saveManifest(); err != nil {
+		return fmt.Errorf("erro ao gravar o manifesto: %w", err)
+	}
+	return nil
+}
+
+func (g *Generator) generateOutputs(ctx context.Context, files []*analyzer.GoFile) error {
+	if g.config.ExportJSON {
+		if err := g.writeExport(files); err != nil {
+			return fmt.Errorf("erro ao exportar JSON: %w", err)
+		}
+	}
+
+	if len(g.config.GraphFormats) > 0 {
+		if err := g.writeGraph(files); err != nil {
+			return fmt.Errorf("erro ao exportar o grafo de dependências: %w", err)
+		}
+	}
+
+	if g.config.Mode == ModeBundle {
+		if err := g.generateBundle(ctx, files); err != nil {
+			return fmt.Errorf("erro ao gerar bundle: %w", err)
+		}
+		return nil
+	}
+
+	if g.config.Mode == ModePackages {
+		if err := g.generatePackageContexts(ctx, files); err != nil {
+			return fmt.Errorf("erro ao gerar contextos de pacotes: %w", err)
+		}
+		return nil
 	}
 
 	// Gerar arquivo de estrutura geral do projeto
@@ -54,74 +294,55 @@ func (g *Generator) GenerateContextFiles(files []*analyzer.GoFile) error {
 	}
 
 	// Gerar arquivos de contexto individuais
-	total := len(files)
-	for i, file := range files {
-		if g.progressCallback != nil {
-			g.progressCallback(i, total)
-		}
+	fileMap := make(map[string]*analyzer.GoFile, len(files))
+	for _, file := range files {
+		fileMap[file.Path] = file
+	}
 
-		if err := g.generateContextFile(file, files); err != nil {
-			return fmt.Errorf("erro ao gerar contexto para %s: %w", file.Name, err)
+	progress := parallel.NewProgress(len(files), g.progressCallback)
+	progress.Start()
+
+	return parallel.ForEach(ctx, len(files), g.config.Workers, func(i int) error {
+		if err := g.generateContextFile(files[i], fileMap); err != nil {
+			return fmt.Errorf("erro ao gerar contexto para %s: %w", files[i].Name, err)
 		}
-	}
+		progress.Done()
+		return nil
+	})
+}
 
-	if g.progressCallback != nil {
-		g.progressCallback(total, total)
+func (g *Generator) generateProjectOverview(files []*analyzer.GoFile) error {
+	overviewName := "00_PROJECT_OVERVIEW" + g.renderer.Extension()
+	if g.projectUpToDate(overviewName) {
+		return nil
 	}
 
-	return nil
+	overview := g.buildOverview(files)
+	return writeOutput(g.outputPath(overviewName), []byte(g.renderer.Overview(overview)))
 }
 
-func (g *Generator) generateProjectOverview(files []*analyzer.GoFile) error {
-	overviewFile := filepath.Join(g.config.OutputDir, "00_PROJECT_OVERVIEW.txt")
-
-	var content strings.Builder
-
-	// Cabeçalho principal
-	content.WriteString("🚀 GO PROJECT COMPLETE OVERVIEW\n")
-	content.WriteString(strings.Repeat("=", 50) + "\n\n")
-	content.WriteString(fmt.Sprintf("📅 Generated: %s\n", time.Now().Format("2006-01-02 15:04:05")))
-	content.WriteString(fmt.Sprintf("📁 Source Directory: %s\n", g.config.SourceDir))
-	content.WriteString(fmt.Sprintf("📊 Total Files Analyzed: %d\n\n", len(files)))
-
-	// Estatísticas do projeto
-	stats := g.calculateProjectStats(files)
-	content.WriteString("📈 PROJECT STATISTICS\n")
-	content.WriteString(strings.Repeat("-", 25) + "\n")
-	content.WriteString(fmt.Sprintf("• Total Go Files: %d\n", stats.TotalFiles))
-	content.WriteString(fmt.Sprintf("• Total Packages: %d\n", stats.TotalPackages))
-	content.WriteString(fmt.Sprintf("• Total Imports: %d\n", stats.TotalImports))
-	content.WriteString(fmt.Sprintf("• Total Lines of Code: %d\n", stats.TotalLOC))
-	content.WriteString(fmt.Sprintf("• Largest File: %s (%d bytes)\n\n", stats.LargestFile, stats.LargestSize))
-
-	// Estrutura de packages
-	content.WriteString("📦 PACKAGE STRUCTURE\n")
-	content.WriteString(strings.Repeat("-", 25) + "\n")
-	g.writePackageStructure(&content, files)
-	content.WriteString("\n")
-
-	// Mapa completo de dependências
-	content.WriteString("🔗 DEPENDENCY MAP\n")
-	content.WriteString(strings.Repeat("-", 20) + "\n")
-	g.writeDependencyMap(&content, files)
-	content.WriteString("\n")
-
-	// Imports externos mais utilizados
-	content.WriteString("📥 TOP EXTERNAL IMPORTS\n")
-	content.WriteString(strings.Repeat("-", 30) + "\n")
-	g.writeTopImports(&content, files)
-
-	// Rodapé
-	content.WriteString("\n" + strings.Repeat("─", 50) + "\n")
-	content.WriteString("🤖 Optimized for AI Context Analysis\n")
-	content.WriteString("⚡ Generated by Go Context Generator Pro v2.0\n")
-
-	return os.WriteFile(overviewFile, []byte(content.String()), 0644)
+// buildOverview reúne os dados da visão geral do projeto.
+func (g *Generator) buildOverview(files []*analyzer.GoFile) *OverviewData {
+	overview := &OverviewData{
+		SourceDir:     g.config.SourceDir,
+		Generated:     g.generated,
+		Stats:         g.stats,
+		Packages:      g.packages,
+		DependencyMap: g.dependencyMap(files),
+		TopImports:    g.topImports(files, 10),
+		Skipped:       g.skippedPaths,
+		Problems:      g.diagnostics,
+	}
+	if g.hasGraphFormat(GraphMermaid) {
+		overview.Graph = g.dependencyGraph(files)
+	}
+	return overview
 }
 
 func (g *Generator) calculateProjectStats(files []*analyzer.GoFile) ProjectStats {
 	stats := ProjectStats{
 		TotalFiles: len(files),
+		Tokenizer:  g.config.Tokenizer.Name(),
 	}
 
 	packages := make(map[string]bool)
@@ -130,6 +351,7 @@ func (g *Generator) calculateProjectStats(files []*analyzer.GoFile) ProjectStats
 	for _, file := range files {
 		packages[file.Package] = true
 		stats.TotalLOC += file.LOC
+		stats.TotalTokens += g.fileTokens[file.Path]
 
 		if file.Size > stats.LargestSize {
 			stats.LargestSize = file.Size
@@ -147,11 +369,12 @@ func (g *Generator) calculateProjectStats(files []*analyzer.GoFile) ProjectStats
 	return stats
 }
 
-func (g *Generator) writePackageStructure(content *strings.Builder, files []*analyzer.GoFile) {
-	packages := make(map[string][]*analyzer.GoFile)
+// packageStructure agrupa os arquivos por pacote, com os pacotes em ordem alfabética.
+func (g *Generator) packageStructure(files []*analyzer.GoFile) []PackageData {
+	packages := make(map[string][]FileData)
 
 	for _, file := range files {
-		packages[file.Package] = append(packages[file.Package], file)
+		packages[file.Package] = append(packages[file.Package], g.fileData(file))
 	}
 
 	var pkgNames []string
@@ -160,73 +383,101 @@ func (g *Generator) writePackageStructure(content *strings.Builder, files []*ana
 	}
 	sort.Strings(pkgNames)
 
+	structure := make([]PackageData, 0, len(pkgNames))
 	for _, pkgName := range pkgNames {
-		pkgFiles := packages[pkgName]
-		content.WriteString(fmt.Sprintf("📦 %s (%d files)\n", pkgName, len(pkgFiles)))
-
-		for _, file := range pkgFiles {
-			relPath, _ := filepath.Rel(g.config.SourceDir, file.Path)
-			content.WriteString(fmt.Sprintf("   ├── %s (%d LOC)\n", relPath, file.LOC))
-		}
-		content.WriteString("\n")
+		structure = append(structure, PackageData{Name: pkgName, Files: packages[pkgName]})
 	}
+
+	return structure
 }
 
-func (g *Generator) writeDependencyMap(content *strings.Builder, files []*analyzer.GoFile) {
+func (g *Generator) dependencyMap(files []*analyzer.GoFile) []DependencyEdge {
+	var edges []DependencyEdge
+
 	for _, file := range files {
 		if len(file.Dependencies) > 0 {
-			relPath, _ := filepath.Rel(g.config.SourceDir, file.Path)
-			content.WriteString(fmt.Sprintf("📄 %s\n", relPath))
-
+			edge := DependencyEdge{File: g.relPath(file.Path)}
 			for _, dep := range file.Dependencies {
-				depRel, _ := filepath.Rel(g.config.SourceDir, dep)
-				content.WriteString(fmt.Sprintf("   └─→ %s\n", depRel))
+				edge.Dependencies = append(edge.Dependencies, g.relPath(dep))
 			}
-			content.WriteString("\n")
+			edges = append(edges, edge)
 		}
 	}
+
+	return edges
 }
 
-func (g *Generator) writeTopImports(content *strings.Builder, files []*analyzer.GoFile) {
+// topImports retorna os imports externos mais usados, do mais frequente ao menos.
+func (g *Generator) topImports(files []*analyzer.GoFile, limit int) []ImportUsage {
 	importCount := make(map[string]int)
 
 	for _, file := range files {
 		for _, imp := range file.Imports {
 			// Filtrar apenas imports externos (não locais do projeto)
-			if !strings.Contains(imp, g.getProjectModule()) {
+			if !g.isLocalImport(imp) {
 				importCount[imp]++
 			}
 		}
 	}
 
 	// Ordenar por frequência
-	type importFreq struct {
-		name  string
-		count int
-	}
-
-	var imports []importFreq
+	var imports []ImportUsage
 	for imp, count := range importCount {
-		imports = append(imports, importFreq{imp, count})
+		imports = append(imports, ImportUsage{Path: imp, Count: count})
 	}
 
 	sort.Slice(imports, func(i, j int) bool {
-		return imports[i].count > imports[j].count
+		if imports[i].Count != imports[j].Count {
+			return imports[i].Count > imports[j].Count
+		}
+		return imports[i].Path < imports[j].Path
 	})
 
-	// Mostrar top 10
-	max := len(imports)
-	if max > 10 {
-		max = 10
+	if len(imports) > limit {
+		imports = imports[:limit]
 	}
 
-	for i := 0; i < max; i++ {
-		imp := imports[i]
-		content.WriteString(fmt.Sprintf("• %s (used %d times)\n", imp.name, imp.count))
-	}
+	return imports
 }
 
+// getProjectModule retorna o módulo resolvido no início da execução ou,
+// fora de uma execução, lê o go.mod.
 func (g *Generator) getProjectModule() string {
+	if g.module != "" {
+		return g.module
+	}
+	return g.readProjectModule()
+}
+
+// isLocalImport indica se imp é código do projeto: um pacote que o scanner
+// resolveu localmente (módulos aninhados, go.work, replace por caminho) ou,
+// para arquivos sem essa resolução, o módulo do go.mod ou um pacote abaixo
+// dele, nunca um módulo que apenas começa com o mesmo prefixo
+// (example.com/proj não contém example.com/project2).
+func (g *Generator) isLocalImport(imp string) bool {
+	if g.localImports[imp] {
+		return true
+	}
+	module := g.getProjectModule()
+	return imp == module || strings.HasPrefix(imp, module+"/")
+}
+
+// resolvedLocalImports reúne os import paths dos arquivos escaneados e os
+// imports que o scanner resolveu para código local.
+func resolvedLocalImports(files []*analyzer.GoFile) map[string]bool {
+	local := make(map[string]bool)
+	for _, file := range files {
+		if file.ImportPath != "" {
+			local[file.ImportPath] = true
+		}
+		for _, imp := range file.LocalImports {
+			local[imp] = true
+		}
+	}
+	return local
+}
+
+func (g *Generator) readProjectModule() string {
 	modPath := filepath.Join(g.config.SourceDir, "go.mod")
 	if content, err := os.ReadFile(modPath); err == nil {
 		lines := strings.Split(string(content), "\n")
@@ -240,105 +491,139 @@ func (g *Generator) getProjectModule() string {
 	return filepath.Base(g.config.SourceDir)
 }
 
-func (g *Generator) generateContextFile(file *analyzer.GoFile, allFiles []*analyzer.GoFile) error {
-	relPath, _ := filepath.Rel(g.config.SourceDir, file.Path)
-	// Criar nome de arquivo mais limpo
-	outputName := strings.ReplaceAll(relPath, string(filepath.Separator), "_")
-	outputName = strings.ReplaceAll(outputName, ".go", "") + "_CONTEXT.txt"
-	outputPath := filepath.Join(g.config.OutputDir, outputName)
-
-	var content strings.Builder
-
-	// Cabeçalho otimizado para IA
-	content.WriteString("🎯 AI CONTEXT FILE\n")
-	content.WriteString(strings.Repeat("=", 30) + "\n\n")
-
-	// Metadados essenciais
-	content.WriteString("📋 FILE METADATA\n")
-	content.WriteString("----------------\n")
-	content.WriteString(fmt.Sprintf("File: %s\n", relPath))
-	content.WriteString(fmt.Sprintf("Package: %s\n", file.Package))
-	content.WriteString(fmt.Sprintf("Lines of Code: %d\n", file.LOC))
-	content.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
-
-	// Imports organizados
-	if len(file.Imports) > 0 {
-		content.WriteString("📥 DEPENDENCIES\n")
-		content.WriteString("---------------\n")
-
-		stdImports, extImports, localImports := g.categorizeImports(file.Imports)
-
-		if len(stdImports) > 0 {
-			content.WriteString("Standard Library:\n")
-			for _, imp := range stdImports {
-				content.WriteString(fmt.Sprintf("  • %s\n", imp))
-			}
-		}
+func (g *Generator) generateContextFile(file *analyzer.GoFile, fileMap map[string]*analyzer.GoFile) error {
+	relPath := filepath.ToSlash(g.relPath(file.Path))
+	outputName := g.fileOutputName(relPath)
+	outputPath := g.outputPath(outputName)
 
-		if len(extImports) > 0 {
-			content.WriteString("External Packages:\n")
-			for _, imp := range extImports {
-				content.WriteString(fmt.Sprintf("  • %s\n", imp))
-			}
-		}
+	// Fora das alterações informadas, manter o contexto anterior sem
+	// percorrer as dependências
+	if g.unaffected(file) && g.manifest.carry(g.config.OutputDir, outputName, relPath) {
+		return nil
+	}
 
-		if len(localImports) > 0 {
-			content.WriteString("Local Project:\n")
-			for _, imp := range localImports {
-				content.WriteString(fmt.Sprintf("  • %s\n", imp))
+	deps, cycles := walkDependencies(file, func(f *analyzer.GoFile) []*analyzer.GoFile {
+		var direct []*analyzer.GoFile
+		for _, depPath := range f.Dependencies {
+			if depFile, exists := fileMap[depPath]; exists {
+				direct = append(direct, depFile)
 			}
 		}
-		content.WriteString("\n")
+		return direct
+	}, g.config.DependencyDepth)
+
+	// Manter o contexto anterior se o arquivo, as dependências incluídas e a
+	// configuração não mudaram
+	inputs := g.contextInputs()
+	g.addFiles(inputs, file)
+	sliced := []string{file.ImportPath}
+	for _, dep := range deps {
+		inputs.add(filePackages(dep)...)
+		g.addFiles(inputs, dep.last())
+		sliced = append(sliced, dep.last().ImportPath)
+	}
+	for _, cycle := range cycles {
+		inputs.add(filePackages(cycle)...)
+	}
+	if g.config.SliceDependencies {
+		g.addPackages(inputs, sliced...)
+	}
+	if g.manifest.upToDate(g.config.OutputDir, outputName, relPath, inputs.sum()) {
+		return nil
+	}
+
+	// Contar tokens e, se exceder o orçamento, descartar dependências
+	view := g.newDependencyView(file)
+	var omitted []dependencyChain[*analyzer.GoFile]
+	data := g.buildContext(file, view, deps, omitted, cycles)
+	tokens := g.countTokens(g.renderer.Context(data))
+	if budget := g.config.MaxTokensPerFile; budget > 0 && tokens > budget {
+		deps, omitted = g.trimDependencies(view, deps, tokens-budget)
+		data = g.buildContext(file, view, deps, omitted, cycles)
+		tokens = g.countTokens(g.renderer.Context(data))
+	}
+
+	data.Tokens = tokens
+	return writeOutput(outputPath, []byte(g.renderer.Context(data)))
+}
+
+// buildContext reúne os dados do contexto de um arquivo. Tokens fica zerado
+// até a contagem sobre o documento renderizado.
+func (g *Generator) buildContext(file *analyzer.GoFile, view *dependencyView, deps, omitted, cycles []dependencyChain[*analyzer.GoFile]) *ContextData {
+	data := &ContextData{
+		File:      g.mainFileData(file),
+		Structure: g.packages,
+		Stats:     g.stats,
+		Tokenizer: g.config.Tokenizer.Name(),
+		Generated: g.generated,
 	}
 
-	// Estrutura simplificada do projeto
-	content.WriteString("🏗️ PROJECT CONTEXT\n")
-	content.WriteString("------------------\n")
-	g.writeSimplifiedStructure(&content, allFiles)
-	content.WriteString("\n")
+	data.Imports.Std, data.Imports.External, data.Imports.Local = g.categorizeImports(file.Imports)
 
-	// Código principal
-	content.WriteString("💻 SOURCE CODE\n")
-	content.WriteString(strings.Repeat("=", 15) + "\n\n")
-	content.WriteString(file.CleanContent)
-	content.WriteString("\n\n")
+	for _, dep := range deps {
+		data.Dependencies = append(data.Dependencies, view.dependencyData(dep))
+	}
+	for _, dep := range omitted {
+		data.Omitted = append(data.Omitted, view.dependencyData(dep))
+	}
+	for _, cycle := range cycles {
+		data.Cycles = append(data.Cycles, filePackages(cycle))
+	}
 
-	// Dependências locais (código relacionado)
-	if len(file.Dependencies) > 0 {
-		content.WriteString("🔗 RELATED CODE\n")
-		content.WriteString(strings.Repeat("=", 15) + "\n\n")
+	return data
+}
 
-		fileMap := make(map[string]*analyzer.GoFile)
-		for _, f := range allFiles {
-			fileMap[f.Path] = f
-		}
+func (v *dependencyView) dependencyData(chain dependencyChain[*analyzer.GoFile]) DependencyData {
+	return DependencyData{
+		FileData: v.fileData(chain.last()),
+		Chain:    filePackages(chain),
+		Depth:    chain.depth(),
+	}
+}
 
-		for i, depPath := range file.Dependencies {
-			if depFile, exists := fileMap[depPath]; exists {
-				depRel, _ := filepath.Rel(g.config.SourceDir, depPath)
+// filePackages converte uma cadeia de arquivos nos nomes de seus pacotes.
+func filePackages(chain dependencyChain[*analyzer.GoFile]) []string {
+	names := make([]string, len(chain))
+	for i, file := range chain {
+		names[i] = file.Package
+	}
+	return names
+}
 
-				content.WriteString(fmt.Sprintf("--- DEPENDENCY %d: %s ---\n", i+1, depRel))
-				content.WriteString(fmt.Sprintf("Package: %s | LOC: %d\n\n", depFile.Package, depFile.LOC))
-				content.WriteString(depFile.CleanContent)
-				content.WriteString("\n\n")
-			}
-		}
+func (g *Generator) fileData(file *analyzer.GoFile) FileData {
+	return FileData{
+		Path:    g.relPath(file.Path),
+		Name:    file.Name,
+		Package: file.Package,
+		LOC:     file.LOC,
+		Size:    file.Size,
+		Tokens:  g.fileTokens[file.Path],
+		Content: file.CleanContent,
+
+		Unparseable: file.Unparseable,
 	}
+}
 
-	// Rodapé otimizado
-	content.WriteString(strings.Repeat("─", 40) + "\n")
-	content.WriteString("🤖 AI-OPTIMIZED CONTEXT\n")
-	content.WriteString("⚡ Tokens minimized for efficient processing\n")
+// mainFileData descreve um arquivo de código principal conforme MainView.
+func (g *Generator) mainFileData(file *analyzer.GoFile) FileData {
+	data := g.fileData(file)
+	if g.config.MainView == ViewSkeleton && file.Skeleton != "" {
+		data.Content = file.Skeleton
+		data.Tokens = g.countTokens(file.Skeleton)
+		data.Skeleton = true
+	}
+	return data
+}
 
-	return os.WriteFile(outputPath, []byte(content.String()), 0644)
+func (g *Generator) relPath(path string) string {
+	relPath, _ := filepath.Rel(g.config.SourceDir, path)
+	return relPath
 }
 
 func (g *Generator) categorizeImports(imports []string) ([]string, []string, []string) {
 	var stdImports, extImports, localImports []string
-	projectModule := g.getProjectModule()
-
 	for _, imp := range imports {
-		if strings.HasPrefix(imp, projectModule) {
+		if g.isLocalImport(imp) {
 			localImports = append(localImports, imp)
 		} else if strings.Contains(imp, ".") {
 			extImports = append(extImports, imp)
@@ -353,27 +638,3 @@ func (g *Generator) categorizeImports(imports []string) ([]string, []string, []s
 
 	return stdImports, extImports, localImports
 }
-
-func (g *Generator) writeSimplifiedStructure(content *strings.Builder, files []*analyzer.GoFile) {
-	packages := make(map[string][]string)
-	for _, file := range files {
-		relPath, _ := filepath.Rel(g.config.SourceDir, file.Path)
-		packages[file.Package] = append(packages[file.Package], filepath.Base(relPath))
-	}
-
-	var pkgNames []string
-	for pkg := range packages {
-		pkgNames = append(pkgNames, pkg)
-	}
-	sort.Strings(pkgNames)
-
-	for _, pkg := range pkgNames {
-		files := packages[pkg]
-		if len(files) <= 3 {
-			content.WriteString(fmt.Sprintf("%s: %s\n", pkg, strings.Join(files, ", ")))
-		} else {
-			content.WriteString(fmt.Sprintf("%s: %s... (+%d more)\n",
-				pkg, strings.Join(files[:3], ", "), len(files)-3))
-		}
-	}
-}
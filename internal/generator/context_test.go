@@ -0,0 +1,191 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/testfixture"
+)
+
+func TestCategorizeImportsMatchesModuleBoundary(t *testing.T) {
+	g := NewGenerator(Config{})
+	g.module = "example.com/proj"
+
+	std, ext, local := g.categorizeImports([]string{
+		"fmt",
+		"example.com/proj",
+		"example.com/proj/internal/db",
+		"example.com/project2/api",
+		"example.com/proj2",
+	})
+
+	if want := []string{"fmt"}; !reflect.DeepEqual(std, want) {
+		t.Errorf("std = %v, want %v", std, want)
+	}
+	if want := []string{"example.com/proj2", "example.com/project2/api"}; !reflect.DeepEqual(ext, want) {
+		t.Errorf("external = %v, want %v", ext, want)
+	}
+	if want := []string{"example.com/proj", "example.com/proj/internal/db"}; !reflect.DeepEqual(local, want) {
+		t.Errorf("local = %v, want %v", local, want)
+	}
+}
+
+func TestLocalImportsFollowScannerResolution(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	root := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("app/go.mod", "module example.com/app\n\ngo 1.21\n\nreplace example.com/shared => ../shared\n")
+	write("app/main.go", "package main\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/util\"\n\t\"example.com/shared/x\"\n\t\"example.com/tools/gen\"\n\t\"github.com/other/pkg\"\n)\n\nfunc main() { fmt.Println(util.U, x.X, gen.G, pkg.P) }\n")
+	write("app/util/util.go", "package util\n\nconst U = 1\n")
+	write("app/tools/go.mod", "module example.com/tools\n\ngo 1.21\n")
+	write("app/tools/gen/gen.go", "package gen\n\nconst G = 1\n")
+	write("shared/go.mod", "module example.com/shared\n\ngo 1.21\n")
+	write("shared/x/x.go", "package x\n\nconst X = 1\n")
+
+	src := filepath.Join(root, "app")
+	files := scanFixture(t, src, 0)
+	g := NewGenerator(Config{SourceDir: src})
+	g.module = g.readProjectModule()
+	g.localImports = resolvedLocalImports(files)
+
+	var main *analyzer.GoFile
+	for _, file := range files {
+		if file.Name == "main.go" {
+			main = file
+		}
+	}
+	if main == nil {
+		t.Fatal("main.go não escaneado")
+	}
+
+	std, ext, local := g.categorizeImports(main.Imports)
+	if want := []string{"fmt"}; !reflect.DeepEqual(std, want) {
+		t.Errorf("std = %v, want %v", std, want)
+	}
+	if want := []string{"github.com/other/pkg"}; !reflect.DeepEqual(ext, want) {
+		t.Errorf("external = %v, want %v", ext, want)
+	}
+	if want := []string{"example.com/app/util", "example.com/shared/x", "example.com/tools/gen"}; !reflect.DeepEqual(local, want) {
+		t.Errorf("local = %v, want %v", local, want)
+	}
+
+	for _, usage := range g.topImports(files, 10) {
+		if usage.Path != "fmt" && usage.Path != "github.com/other/pkg" {
+			t.Errorf("import local %s contado entre os externos", usage.Path)
+		}
+	}
+}
+
+func TestCheckModeRejectsBundleOptions(t *testing.T) {
+	tests := []struct {
+		config Config
+		ok     bool
+	}{
+		{Config{Mode: ModeBundle}, true},
+		{Config{Mode: ModeBundle, MainView: ViewSkeleton}, true},
+		{Config{Mode: ModeBundle, MaxTokensPerFile: 1000}, false},
+		{Config{Mode: ModeBundle, DependencyView: ViewSkeleton}, false},
+		{Config{Mode: ModePerFile, MaxTokensPerFile: 1000, DependencyView: ViewSkeleton}, true},
+		{Config{Mode: ModePackages, MaxTokensPerFile: 1000, DependencyView: ViewSkeleton}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.CheckMode(); (err == nil) != tt.ok {
+			t.Errorf("CheckMode(%+v) = %v", tt.config, err)
+		}
+	}
+}
+
+// scanFixture escaneia um projeto de testfixture com a configuração padrão
+// da CLI.
+func scanFixture(tb testing.TB, dir string, workers int) []*analyzer.GoFile {
+	tb.Helper()
+	scanner := analyzer.NewScanner(analyzer.ScanConfig{
+		RemoveComments:   true,
+		KeepExportedDocs: true,
+		MinifyOutput:     true,
+		Workers:          workers,
+	})
+	files, err := scanner.ScanDirectory(context.Background(), dir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return files
+}
+
+func TestGenerateProgressEndsAtTotal(t *testing.T) {
+	src := t.TempDir()
+	testfixture.Write(t, src, 6, 4)
+	files := scanFixture(t, src, 0)
+
+	for _, mode := range []OutputMode{ModePerFile, ModePackages, ModeBundle} {
+		t.Run(string(mode), func(t *testing.T) {
+			g := NewGenerator(Config{SourceDir: src, OutputDir: t.TempDir(), Mode: mode, Workers: 8})
+
+			last, total := -1, -1
+			g.SetProgressCallback(func(current, n int) {
+				if current < last {
+					t.Errorf("progresso voltou de %d para %d", last, current)
+				}
+				if total != -1 && n != total {
+					t.Errorf("total mudou de %d para %d", total, n)
+				}
+				last, total = current, n
+			})
+
+			if err := g.GenerateContextFiles(context.Background(), files); err != nil {
+				t.Fatal(err)
+			}
+			if last != total || total <= 0 {
+				t.Errorf("última chamada = (%d, %d), want (total, total)", last, total)
+			}
+		})
+	}
+}
+
+// BenchmarkGenerateContextFiles compara a geração sequencial com a paralela,
+// reescrevendo todos os documentos a cada volta:
+//
+//	go test -run '^$' -bench GenerateContextFiles ./internal/generator/
+func BenchmarkGenerateContextFiles(b *testing.B) {
+	src := b.TempDir()
+	testfixture.Write(b, src, 40, 10)
+	files := scanFixture(b, src, 0)
+
+	workerCounts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		workerCounts = append(workerCounts, n)
+	}
+
+	for _, mode := range []OutputMode{ModePerFile, ModePackages} {
+		for _, workers := range workerCounts {
+			b.Run(fmt.Sprintf("%s/workers=%d", mode, workers), func(b *testing.B) {
+				config := Config{
+					SourceDir: src,
+					OutputDir: b.TempDir(),
+					Mode:      mode,
+					Workers:   workers,
+					Force:     true,
+				}
+				for i := 0; i < b.N; i++ {
+					if err := NewGenerator(config).GenerateContextFiles(context.Background(), files); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
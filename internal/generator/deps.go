@@ -0,0 +1,70 @@
+package generator
+
+import "strings"
+
+// DepthAll inclui o fecho transitivo completo das dependências.
+const DepthAll = -1
+
+// dependencyChain é um caminho no grafo de imports, do contexto principal
+// até o nó alcançado (inclusive).
+type dependencyChain[T comparable] []T
+
+func (c dependencyChain[T]) last() T { return c[len(c)-1] }
+
+// depth é o número de imports entre o contexto principal e o nó.
+func (c dependencyChain[T]) depth() int { return len(c) - 1 }
+
+// walkDependencies percorre o grafo em largura a partir de root, até
+// maxDepth níveis (DepthAll percorre tudo; 0 equivale a 1). Cada nó aparece
+// uma vez, pelo caminho mais curto, com os nós diretos antes dos indiretos.
+// Arestas que voltam para um nó do próprio caminho são ciclos e são
+// devolvidas fechadas (o primeiro nó repetido no fim).
+func walkDependencies[T comparable](root T, edges func(T) []T, maxDepth int) (reached, cycles []dependencyChain[T]) {
+	if maxDepth == 0 {
+		maxDepth = 1
+	}
+
+	visited := map[T]bool{root: true}
+	queue := []dependencyChain[T]{{root}}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if maxDepth > 0 && current.depth() >= maxDepth {
+			continue
+		}
+
+		for _, next := range edges(current.last()) {
+			if cycle := closeCycle(current, next); cycle != nil {
+				cycles = append(cycles, cycle)
+				continue
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+
+			chain := append(append(dependencyChain[T]{}, current...), next)
+			reached = append(reached, chain)
+			queue = append(queue, chain)
+		}
+	}
+
+	return reached, cycles
+}
+
+// closeCycle retorna o ciclo formado se next já está no caminho.
+func closeCycle[T comparable](chain dependencyChain[T], next T) dependencyChain[T] {
+	for i, node := range chain {
+		if node == next {
+			return append(append(dependencyChain[T]{}, chain[i:]...), next)
+		}
+	}
+	return nil
+}
+
+// formatChain junta uma cadeia de pacotes como "handlers → service → repo".
+func formatChain(chain []string) string {
+	return strings.Join(chain, " → ")
+}
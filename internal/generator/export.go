@@ -0,0 +1,216 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// ExportSchemaVersion identifica o esquema de project.json e files.jsonl.
+// Deve ser incrementado a cada mudança incompatível (remoção ou mudança de
+// significado de um campo); campos novos não alteram a versão.
+const ExportSchemaVersion = 1
+
+// Nomes dos arquivos da exportação estruturada
+const (
+	ProjectExportFile = "project.json"
+	FilesExportFile   = "files.jsonl"
+)
+
+// ProjectExport é o conteúdo de project.json: metadados, estatísticas e o
+// grafo de dependências entre arquivos. Caminhos são relativos à pasta de
+// origem e sempre usam "/" como separador.
+type ProjectExport struct {
+	SchemaVersion int              `json:"schema_version"`
+	GeneratedAt   *time.Time       `json:"generated_at,omitempty"` // RFC 3339; ausente com OmitTimestamps
+	SourceDir     string           `json:"source_dir"`
+	Module        string           `json:"module"` // Caminho do módulo em go.mod
+	Stats         ExportStats      `json:"stats"`
+	Packages      []ExportPackage  `json:"packages"`
+	Dependencies  []ExportEdge     `json:"dependencies"` // Apenas arquivos com dependências locais
+	Skipped       []ExportSkipPath `json:"skipped,omitempty"`
+	Problems      []ExportProblem  `json:"problems,omitempty"`
+}
+
+// ExportStats espelha ProjectStats.
+type ExportStats struct {
+	TotalFiles    int    `json:"total_files"`
+	TotalPackages int    `json:"total_packages"`
+	TotalImports  int    `json:"total_imports"` // Imports distintos
+	TotalLOC      int    `json:"total_loc"`
+	TotalTokens   int    `json:"total_tokens"` // Tokens do código limpo
+	Tokenizer     string `json:"tokenizer"`
+	LargestFile   string `json:"largest_file"`
+	LargestSize   int64  `json:"largest_size"` // Em bytes
+}
+
+// ExportPackage lista os arquivos de um pacote.
+type ExportPackage struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// ExportEdge liga um arquivo às dependências locais que ele importa.
+type ExportEdge struct {
+	File         string   `json:"file"`
+	Dependencies []string `json:"dependencies"`
+}
+
+// ExportSkipPath registra um caminho excluído do escaneamento.
+type ExportSkipPath struct {
+	Path  string `json:"path"`
+	IsDir bool   `json:"is_dir"`
+	Rule  string `json:"rule"`
+}
+
+// ExportProblem registra um arquivo que não pôde ser interpretado.
+type ExportProblem struct {
+	Path     string   `json:"path"`
+	Reason   string   `json:"reason"` // "syntax error", "merge conflict" ou "read error"
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Errors   []string `json:"errors"`
+	Included bool     `json:"included"` // Presente em files.jsonl como código bruto
+}
+
+// FileExport é uma linha de files.jsonl.
+type FileExport struct {
+	SchemaVersion int      `json:"schema_version"`
+	Path          string   `json:"path"`
+	Name          string   `json:"name"`
+	Package       string   `json:"package"`
+	Imports       []string `json:"imports"`      // Na ordem do arquivo
+	Dependencies  []string `json:"dependencies"` // Arquivos locais, relativos à origem
+	LOC           int      `json:"loc"`
+	Size          int64    `json:"size"` // Em bytes, do arquivo original
+	Tokens        int      `json:"tokens"`
+	Content       string   `json:"content"`               // Código limpo (comentários/minificação conforme a configuração)
+	Unparseable   bool     `json:"unparseable,omitempty"` // Content é o código original, com erro de sintaxe
+}
+
+// Export monta em memória os dados da exportação estruturada, sem escrever
+// nada em disco. Os registros de arquivos seguem a ordem de files.
+func (g *Generator) Export(files []*analyzer.GoFile) (*ProjectExport, []FileExport) {
+	generated := g.generated
+	if g.fileTokens == nil {
+		g.module = g.readProjectModule()
+		g.localImports = resolvedLocalImports(files)
+		g.countFileTokens(context.Background(), files)
+		generated = g.timestamp()
+	}
+
+	stats := g.calculateProjectStats(files)
+	project := &ProjectExport{
+		SchemaVersion: ExportSchemaVersion,
+		SourceDir:     g.config.SourceDir,
+		Module:        g.getProjectModule(),
+		Stats: ExportStats{
+			TotalFiles:    stats.TotalFiles,
+			TotalPackages: stats.TotalPackages,
+			TotalImports:  stats.TotalImports,
+			TotalLOC:      stats.TotalLOC,
+			TotalTokens:   stats.TotalTokens,
+			Tokenizer:     stats.Tokenizer,
+			LargestFile:   stats.LargestFile,
+			LargestSize:   stats.LargestSize,
+		},
+		Packages:     []ExportPackage{},
+		Dependencies: []ExportEdge{},
+	}
+	if !generated.IsZero() {
+		project.GeneratedAt = &generated
+	}
+
+	for _, pkg := range g.packageStructure(files) {
+		exported := ExportPackage{Name: pkg.Name, Files: []string{}}
+		for _, file := range pkg.Files {
+			exported.Files = append(exported.Files, filepath.ToSlash(file.Path))
+		}
+		project.Packages = append(project.Packages, exported)
+	}
+
+	for _, edge := range g.dependencyMap(files) {
+		exported := ExportEdge{File: filepath.ToSlash(edge.File), Dependencies: []string{}}
+		for _, dep := range edge.Dependencies {
+			exported.Dependencies = append(exported.Dependencies, filepath.ToSlash(dep))
+		}
+		project.Dependencies = append(project.Dependencies, exported)
+	}
+
+	for _, skipped := range g.skippedPaths {
+		project.Skipped = append(project.Skipped, ExportSkipPath{Path: skipped.Path, IsDir: skipped.IsDir, Rule: skipped.Rule})
+	}
+
+	for _, problem := range g.diagnostics {
+		project.Problems = append(project.Problems, ExportProblem{
+			Path:     problem.Path,
+			Reason:   problem.Reason,
+			Line:     problem.Line,
+			Column:   problem.Column,
+			Errors:   append([]string{}, problem.Errors...),
+			Included: problem.Included,
+		})
+	}
+
+	records := make([]FileExport, 0, len(files))
+	for _, file := range files {
+		record := FileExport{
+			SchemaVersion: ExportSchemaVersion,
+			Path:          filepath.ToSlash(g.relPath(file.Path)),
+			Name:          file.Name,
+			Package:       file.Package,
+			Imports:       append([]string{}, file.Imports...),
+			Dependencies:  []string{},
+			LOC:           file.LOC,
+			Size:          file.Size,
+			Tokens:        g.fileTokens[file.Path],
+			Content:       file.CleanContent,
+			Unparseable:   file.Unparseable,
+		}
+		for _, dep := range file.Dependencies {
+			record.Dependencies = append(record.Dependencies, filepath.ToSlash(g.relPath(dep)))
+		}
+		records = append(records, record)
+	}
+
+	return project, records
+}
+
+// writeExport grava project.json e files.jsonl na pasta de saída, se as
+// entradas mudaram desde a geração anterior.
+func (g *Generator) writeExport(files []*analyzer.GoFile) error {
+	projectKept := g.projectUpToDate(ProjectExportFile)
+	filesKept := g.projectUpToDate(FilesExportFile)
+	if projectKept && filesKept {
+		return nil
+	}
+	project, records := g.Export(files)
+
+	data, err := json.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return err
+	}
+	if !projectKept {
+		if err := writeOutput(g.outputPath(ProjectExportFile), append(data, '\n')); err != nil {
+			return err
+		}
+	}
+	if filesKept {
+		return nil
+	}
+
+	var lines bytes.Buffer
+	enc := json.NewEncoder(&lines)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return fmt.Errorf("erro ao exportar %s: %w", record.Path, err)
+		}
+	}
+
+	return writeOutput(g.outputPath(FilesExportFile), lines.Bytes())
+}
@@ -0,0 +1,424 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// GraphFormat é um formato de exportação do grafo de dependências.
+type GraphFormat string
+
+const (
+	// GraphDOT grava o grafo para o Graphviz (dot -Tsvg).
+	GraphDOT GraphFormat = "dot"
+	// GraphMermaid grava um flowchart Mermaid, que também entra na visão
+	// geral Markdown.
+	GraphMermaid GraphFormat = "mermaid"
+	// GraphJSON grava o grafo como lista de adjacência.
+	GraphJSON GraphFormat = "json"
+)
+
+// Nomes dos arquivos do grafo, por formato
+var graphFiles = map[GraphFormat]string{
+	GraphDOT:     "dependencies.dot",
+	GraphMermaid: "dependencies.mmd",
+	GraphJSON:    "dependencies.json",
+}
+
+// ParseGraphFormats converte uma lista separada por vírgulas, como
+// "dot,json"; vazio desativa a exportação.
+func ParseGraphFormats(list string) ([]GraphFormat, error) {
+	var formats []GraphFormat
+	seen := make(map[GraphFormat]bool)
+	for _, name := range strings.Split(list, ",") {
+		format := GraphFormat(strings.ToLower(strings.TrimSpace(name)))
+		if format == "" || seen[format] {
+			continue
+		}
+		if _, ok := graphFiles[format]; !ok {
+			return nil, fmt.Errorf("formato de grafo desconhecido: %q", name)
+		}
+		seen[format] = true
+		formats = append(formats, format)
+	}
+	return formats, nil
+}
+
+// GraphLevel define os nós do grafo de dependências.
+type GraphLevel string
+
+const (
+	// GraphFiles liga cada arquivo aos arquivos locais que ele importa (padrão).
+	GraphFiles GraphLevel = "files"
+	// GraphPackages agrupa os arquivos em pacotes, um nó por import path.
+	GraphPackages GraphLevel = "packages"
+)
+
+// ParseGraphLevel converte o nome de um nível; vazio resulta em GraphFiles.
+func ParseGraphLevel(name string) (GraphLevel, error) {
+	switch GraphLevel(strings.ToLower(strings.TrimSpace(name))) {
+	case "", GraphFiles:
+		return GraphFiles, nil
+	case GraphPackages:
+		return GraphPackages, nil
+	}
+	return "", fmt.Errorf("nível do grafo desconhecido: %q", name)
+}
+
+// DependencyGraph é o grafo de dependências locais do projeto, com os nós e
+// as arestas em ordem alfabética. Os ids são caminhos relativos à origem
+// (com "/") ou import paths, conforme Level.
+type DependencyGraph struct {
+	Level  GraphLevel
+	Nodes  []GraphNode
+	Edges  []GraphEdge
+	Cycles [][]string // Grupos de nós que se importam mutuamente
+}
+
+// GraphNode é um arquivo ou um pacote do grafo.
+type GraphNode struct {
+	ID      string
+	Package string // Nome do pacote
+	InCycle bool
+}
+
+// GraphEdge liga um nó a uma dependência. InCycle marca as arestas que
+// fazem parte de um ciclo de import.
+type GraphEdge struct {
+	From, To string
+	InCycle  bool
+}
+
+func (g *Generator) hasGraphFormat(format GraphFormat) bool {
+	for _, f := range g.config.GraphFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// dependencyGraph monta o grafo no nível configurado e marca os ciclos.
+func (g *Generator) dependencyGraph(files []*analyzer.GoFile) *DependencyGraph {
+	graph := &DependencyGraph{Level: g.config.GraphLevel}
+	adjacency := make(map[string][]string)
+
+	if graph.Level == GraphPackages {
+		groups := g.groupPackages(files)
+		for _, group := range groups {
+			graph.Nodes = append(graph.Nodes, GraphNode{ID: group.importPath, Package: group.name})
+			for _, other := range groups {
+				if other != group && group.imports[other.importPath] {
+					adjacency[group.importPath] = append(adjacency[group.importPath], other.importPath)
+				}
+			}
+		}
+	} else {
+		graph.Level = GraphFiles
+		for _, file := range files {
+			id := filepath.ToSlash(g.relPath(file.Path))
+			graph.Nodes = append(graph.Nodes, GraphNode{ID: id, Package: file.Package})
+			for _, dep := range file.Dependencies {
+				adjacency[id] = append(adjacency[id], filepath.ToSlash(g.relPath(dep)))
+			}
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].ID < graph.Nodes[j].ID })
+
+	ids := make([]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		ids[i] = node.ID
+		sort.Strings(adjacency[node.ID])
+	}
+
+	component := make(map[string]int)
+	for i, cycle := range stronglyConnected(ids, adjacency) {
+		if len(cycle) == 1 && !containsString(adjacency[cycle[0]], cycle[0]) {
+			continue
+		}
+		graph.Cycles = append(graph.Cycles, cycle)
+		for _, id := range cycle {
+			component[id] = i + 1
+		}
+	}
+	sort.Slice(graph.Cycles, func(i, j int) bool { return graph.Cycles[i][0] < graph.Cycles[j][0] })
+
+	for i := range graph.Nodes {
+		graph.Nodes[i].InCycle = component[graph.Nodes[i].ID] != 0
+	}
+	for _, from := range ids {
+		for _, to := range adjacency[from] {
+			graph.Edges = append(graph.Edges, GraphEdge{
+				From:    from,
+				To:      to,
+				InCycle: component[from] != 0 && component[from] == component[to],
+			})
+		}
+	}
+
+	return graph
+}
+
+// stronglyConnected separa os nós em componentes fortemente conexos
+// (Tarjan); cada componente vem com os nós em ordem alfabética.
+func stronglyConnected(ids []string, adjacency map[string][]string) [][]string {
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	var components [][]string
+
+	var visit func(id string)
+	visit = func(id string) {
+		index[id] = len(index) + 1
+		low[id] = index[id]
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, next := range adjacency[id] {
+			if index[next] == 0 {
+				visit(next)
+				low[id] = min(low[id], low[next])
+			} else if onStack[next] {
+				low[id] = min(low[id], index[next])
+			}
+		}
+
+		if low[id] == index[id] {
+			var component []string
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == id {
+					break
+				}
+			}
+			sort.Strings(component)
+			components = append(components, component)
+		}
+	}
+
+	for _, id := range ids {
+		if index[id] == 0 {
+			visit(id)
+		}
+	}
+	return components
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// writeGraph grava o grafo de dependências nos formatos configurados cujas
+// entradas mudaram desde a geração anterior.
+func (g *Generator) writeGraph(files []*analyzer.GoFile) error {
+	graph := g.dependencyGraph(files)
+
+	for _, format := range g.config.GraphFormats {
+		name := graphFiles[format]
+		if g.projectUpToDate(name) {
+			continue
+		}
+
+		var content []byte
+		switch format {
+		case GraphDOT:
+			content = []byte(graph.DOT())
+		case GraphMermaid:
+			content = []byte(graph.Mermaid())
+		case GraphJSON:
+			data, err := json.MarshalIndent(graph.adjacencyList(), "", "  ")
+			if err != nil {
+				return err
+			}
+			content = append(data, '\n')
+		}
+
+		if err := writeOutput(g.outputPath(name), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DOT retorna o grafo na linguagem do Graphviz. No nível de arquivos, os
+// arquivos de cada pacote ficam agrupados em um cluster; nós e arestas de
+// ciclos aparecem em vermelho.
+func (graph *DependencyGraph) DOT() string {
+	var content strings.Builder
+	content.WriteString("digraph dependencies {\n")
+	content.WriteString("  rankdir=LR;\n")
+	content.WriteString("  node [shape=box, fontname=\"Helvetica\", fontsize=10];\n")
+	content.WriteString("  edge [color=\"#555555\"];\n")
+
+	writeNode := func(indent string, node GraphNode) {
+		content.WriteString(indent + dotQuote(node.ID) + " [label=" + dotQuote(graph.label(node)))
+		if node.InCycle {
+			content.WriteString(", color=\"#d62728\", fontcolor=\"#d62728\", penwidth=2")
+		}
+		content.WriteString("];\n")
+	}
+
+	if graph.Level == GraphPackages {
+		for _, node := range graph.Nodes {
+			writeNode("  ", node)
+		}
+	} else {
+		for i, dir := range graph.dirs() {
+			content.WriteString(fmt.Sprintf("  subgraph cluster_%d {\n", i))
+			content.WriteString("    label=" + dotQuote(dir) + ";\n")
+			content.WriteString("    style=rounded; color=\"#bbbbbb\";\n")
+			for _, node := range graph.Nodes {
+				if path.Dir(node.ID) == dir {
+					writeNode("    ", node)
+				}
+			}
+			content.WriteString("  }\n")
+		}
+	}
+
+	for _, edge := range graph.Edges {
+		content.WriteString("  " + dotQuote(edge.From) + " -> " + dotQuote(edge.To))
+		if edge.InCycle {
+			content.WriteString(" [color=\"#d62728\", penwidth=2]")
+		}
+		content.WriteString(";\n")
+	}
+
+	content.WriteString("}\n")
+	return content.String()
+}
+
+// Mermaid retorna o grafo como flowchart Mermaid, com os arquivos em um
+// subgraph por pasta, os ciclos na classe "cycle" e as arestas de ciclos
+// destacadas por linkStyle.
+func (graph *DependencyGraph) Mermaid() string {
+	var content strings.Builder
+	content.WriteString("flowchart LR\n")
+
+	ids := make(map[string]string, len(graph.Nodes))
+	var cycleNodes []string
+	for i, node := range graph.Nodes {
+		ids[node.ID] = fmt.Sprintf("n%d", i)
+		if node.InCycle {
+			cycleNodes = append(cycleNodes, ids[node.ID])
+		}
+	}
+
+	writeNode := func(indent string, node GraphNode) {
+		content.WriteString(fmt.Sprintf("%s%s[\"%s\"]\n", indent, ids[node.ID], mermaidLabel(graph.label(node))))
+	}
+	if graph.Level == GraphPackages {
+		for _, node := range graph.Nodes {
+			writeNode("  ", node)
+		}
+	} else {
+		for i, dir := range graph.dirs() {
+			content.WriteString(fmt.Sprintf("  subgraph d%d[\"%s\"]\n", i, mermaidLabel(dir)))
+			for _, node := range graph.Nodes {
+				if path.Dir(node.ID) == dir {
+					writeNode("    ", node)
+				}
+			}
+			content.WriteString("  end\n")
+		}
+	}
+
+	var cycleEdges []string
+	for i, edge := range graph.Edges {
+		content.WriteString("  " + ids[edge.From] + " --> " + ids[edge.To] + "\n")
+		if edge.InCycle {
+			cycleEdges = append(cycleEdges, fmt.Sprint(i))
+		}
+	}
+
+	if len(cycleNodes) > 0 {
+		content.WriteString("  classDef cycle stroke:#d62728,stroke-width:2px,color:#d62728\n")
+		content.WriteString("  class " + strings.Join(cycleNodes, ",") + " cycle\n")
+	}
+	if len(cycleEdges) > 0 {
+		content.WriteString("  linkStyle " + strings.Join(cycleEdges, ",") + " stroke:#d62728,stroke-width:2px\n")
+	}
+	return content.String()
+}
+
+// graphExport é o conteúdo de dependencies.json.
+type graphExport struct {
+	SchemaVersion int                 `json:"schema_version"`
+	Level         GraphLevel          `json:"level"`
+	Nodes         []graphExportNode   `json:"nodes"`
+	Adjacency     map[string][]string `json:"adjacency"` // Todo nó tem uma entrada, mesmo sem dependências
+	Cycles        [][]string          `json:"cycles"`
+}
+
+type graphExportNode struct {
+	ID      string `json:"id"`
+	Package string `json:"package"`
+	InCycle bool   `json:"in_cycle,omitempty"`
+}
+
+func (graph *DependencyGraph) adjacencyList() graphExport {
+	export := graphExport{
+		SchemaVersion: ExportSchemaVersion,
+		Level:         graph.Level,
+		Nodes:         []graphExportNode{},
+		Adjacency:     make(map[string][]string, len(graph.Nodes)),
+		Cycles:        [][]string{},
+	}
+	for _, node := range graph.Nodes {
+		export.Nodes = append(export.Nodes, graphExportNode{ID: node.ID, Package: node.Package, InCycle: node.InCycle})
+		export.Adjacency[node.ID] = []string{}
+	}
+	for _, edge := range graph.Edges {
+		export.Adjacency[edge.From] = append(export.Adjacency[edge.From], edge.To)
+	}
+	export.Cycles = append(export.Cycles, graph.Cycles...)
+	return export
+}
+
+// label é o texto de um nó: o nome do arquivo (a pasta já está no cluster)
+// ou o import path do pacote.
+func (graph *DependencyGraph) label(node GraphNode) string {
+	if graph.Level == GraphPackages {
+		return node.ID
+	}
+	return path.Base(node.ID)
+}
+
+// dirs retorna as pastas dos arquivos do grafo, em ordem alfabética.
+func (graph *DependencyGraph) dirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, node := range graph.Nodes {
+		if dir := path.Dir(node.ID); !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// dotQuote retorna value entre aspas, escapado para o DOT.
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// mermaidLabel escapa o texto de um nó Mermaid entre aspas.
+func mermaidLabel(value string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(value)
+}
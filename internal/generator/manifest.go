@@ -0,0 +1,389 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/parallel"
+	"go-context-generator/internal/prompt"
+)
+
+// ManifestFile guarda, na pasta de saída, as entradas de cada documento da
+// última geração. Com ele, uma nova execução reescreve apenas os contextos
+// cujas entradas mudaram e apaga os que não são mais produzidos.
+const ManifestFile = ".go-context-manifest.json"
+
+// manifestVersion invalida os manifestos antigos quando o formato dos
+// documentos, o cálculo das entradas ou os nomes dos documentos mudam.
+const manifestVersion = 2
+
+// manifest é o conteúdo de ManifestFile.
+type manifest struct {
+	Version   int                       `json:"version"`
+	Settings  string                    `json:"settings"`  // Hash da configuração que afeta os documentos
+	Structure string                    `json:"structure"` // Hash dos nomes de pacotes e arquivos
+	Sources   map[string]string         `json:"sources"`   // Arquivo de origem (relativo) → hash do conteúdo
+	Outputs   map[string]manifestOutput `json:"outputs"`   // Nome do documento (com barras) → entradas
+	Contexts  map[string]string         `json:"contexts"`  // Arquivo ou import path de origem → nome do documento
+}
+
+// manifestOutput descreve um documento gerado. Documentos do projeto inteiro
+// (visão geral, bundle, exportação, grafo) não têm Source.
+type manifestOutput struct {
+	Source string `json:"source,omitempty"` // Arquivo ou import path de origem; vazio no projeto inteiro
+	Inputs string `json:"inputs,omitempty"` // Hash do arquivo, das dependências e da estrutura
+}
+
+// outputManifest acompanha uma geração: o manifesto anterior, lido da pasta
+// de saída, e o atual, preenchido pelos workers.
+type outputManifest struct {
+	previous  *manifest // nil = nenhuma geração anterior
+	current   *manifest
+	reuse     bool              // false com Force
+	structure string            // Hash dos nomes de pacotes e arquivos
+	project   string            // Entradas dos documentos do projeto inteiro (ver projectInputs)
+	sources   map[string]string // Caminho absoluto → hash do conteúdo
+	members   map[string][]*analyzer.GoFile
+	mu        sync.Mutex
+	reused    int
+	conflict  error // Duas origens com o mesmo nome de documento
+}
+
+// loadManifest lê o manifesto da geração anterior e calcula os hashes dos
+// arquivos de origem. Com Force, nenhum documento é reaproveitado, mas o
+// manifesto anterior ainda indica os documentos órfãos a apagar.
+func (g *Generator) loadManifest(ctx context.Context, files []*analyzer.GoFile) error {
+	hashes := make([]string, len(files))
+	err := parallel.ForEach(ctx, len(files), g.config.Workers, func(i int) error {
+		hashes[i] = sourceHash(files[i])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	m := &outputManifest{
+		current: &manifest{
+			Version:  manifestVersion,
+			Settings: g.settingsHash(),
+			Sources:  make(map[string]string, len(files)),
+			Outputs:  make(map[string]manifestOutput),
+		},
+		sources: make(map[string]string, len(files)),
+		members: make(map[string][]*analyzer.GoFile),
+		reuse:   !g.config.Force,
+	}
+	for i, file := range files {
+		m.sources[file.Path] = hashes[i]
+		m.current.Sources[filepath.ToSlash(g.relPath(file.Path))] = hashes[i]
+		m.members[file.ImportPath] = append(m.members[file.ImportPath], file)
+	}
+
+	structure := newInputHash()
+	for _, pkg := range g.packages {
+		structure.add(pkg.Name)
+		for _, file := range pkg.Files {
+			structure.add(file.Path)
+		}
+	}
+	m.structure = structure.sum()
+	m.current.Structure = m.structure
+
+	if data, err := os.ReadFile(filepath.Join(g.config.OutputDir, ManifestFile)); err == nil {
+		var previous manifest
+		if json.Unmarshal(data, &previous) == nil && previous.Version == manifestVersion {
+			m.previous = &previous
+		}
+	}
+
+	g.manifest = m
+	return nil
+}
+
+// saveManifest apaga os documentos da geração anterior que não foram
+// produzidos agora e grava o novo manifesto, por último: uma geração
+// interrompida antes disso é comparada ao manifesto anterior na próxima.
+func (g *Generator) saveManifest() error {
+	m := g.manifest
+	if m.previous != nil {
+		for name := range m.previous.Outputs {
+			if _, produced := m.current.Outputs[name]; produced || !safeOutputName(name) {
+				continue
+			}
+			if err := g.removeOutput(name); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.current.Contexts = make(map[string]string)
+	for name, output := range m.current.Outputs {
+		if output.Source != "" {
+			m.current.Contexts[output.Source] = name
+		}
+	}
+
+	data, err := json.MarshalIndent(m.current, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeOutput(filepath.Join(g.config.OutputDir, ManifestFile), append(data, '\n'))
+}
+
+// safeOutputName impede que um manifesto adulterado apague arquivos fora da
+// pasta de saída.
+func safeOutputName(name string) bool {
+	return name != ManifestFile && filepath.IsLocal(filepath.FromSlash(name))
+}
+
+// removeOutput apaga um documento da pasta de saída e as pastas que ficaram
+// vazias no caminho dele (esquema NamingMirror).
+func (g *Generator) removeOutput(name string) error {
+	path := filepath.Join(g.config.OutputDir, filepath.FromSlash(name))
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for dir := filepath.Dir(path); dir != filepath.Clean(g.config.OutputDir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break // Não vazia
+		}
+	}
+	return nil
+}
+
+// upToDate informa se o documento foi gerado na execução anterior com as
+// mesmas entradas e ainda existe. Em ambos os casos ele é registrado no
+// manifesto atual.
+func (m *outputManifest) upToDate(outputDir, name, source, inputs string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if other, exists := m.current.Outputs[name]; exists && other.Source != source && m.conflict == nil {
+		m.conflict = fmt.Errorf("%s e %s resultam no mesmo documento %s", other.Source, source, name)
+	}
+	m.current.Outputs[name] = manifestOutput{Source: source, Inputs: inputs}
+	if !m.reuse || m.previous == nil || m.previous.Settings != m.current.Settings || inputs == "" {
+		return false
+	}
+	if m.previous.Outputs[name].Inputs != inputs {
+		return false
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err != nil {
+		return false
+	}
+	m.reused++
+	return true
+}
+
+// carry mantém o documento da execução anterior sem comparar as entradas,
+// copiando-as do manifesto anterior, desde que a configuração e a estrutura
+// de pacotes sejam as mesmas e o documento ainda exista.
+func (m *outputManifest) carry(outputDir, name, source string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.reuse || m.previous == nil || m.previous.Settings != m.current.Settings || m.previous.Structure != m.current.Structure {
+		return false
+	}
+	previous, ok := m.previous.Outputs[name]
+	if !ok || previous.Source != source || previous.Inputs == "" {
+		return false
+	}
+	if _, exists := m.current.Outputs[name]; exists {
+		return false // Conflito de nomes, detectado por upToDate
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name))); err != nil {
+		return false
+	}
+	m.current.Outputs[name] = previous
+	m.reused++
+	return true
+}
+
+// projectUpToDate informa se um documento do projeto inteiro pode ser
+// mantido, registrando-o no manifesto atual (ver upToDate).
+func (g *Generator) projectUpToDate(name string) bool {
+	return g.manifest.upToDate(g.config.OutputDir, name, "", g.manifest.project)
+}
+
+// Written retorna quantos documentos (visão geral, contextos, bundle,
+// exportações) a última geração gravou, sem contar os mantidos (ver Reused).
+func (g *Generator) Written() int {
+	if g.manifest == nil {
+		return 0
+	}
+	g.manifest.mu.Lock()
+	defer g.manifest.mu.Unlock()
+	return len(g.manifest.current.Outputs) - g.manifest.reused
+}
+
+// Reused retorna quantos documentos da última geração foram mantidos por não
+// terem mudado desde a execução anterior.
+func (g *Generator) Reused() int {
+	if g.manifest == nil {
+		return 0
+	}
+	g.manifest.mu.Lock()
+	defer g.manifest.mu.Unlock()
+	return g.manifest.reused
+}
+
+// settingsHash resume a configuração que afeta o conteúdo dos documentos.
+// Workers e o caminho de saída não entram; o módulo entra porque separa os
+// imports locais dos externos.
+func (g *Generator) settingsHash() string {
+	settings, _ := json.Marshal(struct {
+		Mode              OutputMode
+		Format            Format
+		RemoveComments    bool
+		MinifyOutput      bool
+		Tokenizer         string
+		MaxTokensPerFile  int
+		DependencyDepth   int
+		SliceDependencies bool
+		MainView          CodeView
+		DependencyView    CodeView
+		SourceDir         string
+		Module            string
+		OmitTimestamps    bool
+		Timestamp         int64  // Apenas uma data fixa; o horário atual invalidaria tudo
+		Template          string // Hash do texto do template
+		Prompt            string // Texto do prompt, sem os placeholders preenchidos
+		PromptPosition    PromptPosition
+	}{
+		g.config.Mode, g.config.Format, g.config.RemoveComments, g.config.MinifyOutput,
+		g.config.Tokenizer.Name(), g.config.MaxTokensPerFile, g.config.DependencyDepth,
+		g.config.SliceDependencies, g.config.MainView, g.config.DependencyView,
+		g.config.SourceDir, g.getProjectModule(),
+		g.config.OmitTimestamps, fixedTimestamp(g.config.Timestamp), templateDigest(g.config.Template),
+		promptSource(g.config.Prompt), g.config.PromptPosition,
+	})
+
+	h := newInputHash()
+	h.add(strconv.Itoa(manifestVersion), string(settings))
+	return h.sum()
+}
+
+func templateDigest(t *Template) string {
+	if t == nil {
+		return ""
+	}
+	return t.digest
+}
+
+func promptSource(p *prompt.Prompt) string {
+	if p == nil {
+		return ""
+	}
+	return p.Source
+}
+
+func fixedTimestamp(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// sourceHash resume tudo o que os documentos usam de um arquivo: o código
+// original (LOC, tamanho, declarações) e as versões limpas.
+func sourceHash(file *analyzer.GoFile) string {
+	h := newInputHash()
+	h.add(file.Package, file.ImportPath, file.Content, file.CleanContent, file.Skeleton, file.API,
+		strconv.FormatBool(file.Unparseable))
+	h.add(file.LocalImports...)
+	return h.sum()
+}
+
+// inputHash acumula as entradas de um documento. Cada parte é seguida de um
+// separador, para que ("ab", "c") e ("a", "bc") resultem em hashes distintos.
+type inputHash struct {
+	hash.Hash
+}
+
+func newInputHash() inputHash {
+	return inputHash{sha256.New()}
+}
+
+func (h inputHash) add(parts ...string) {
+	for _, part := range parts {
+		io.WriteString(h, part)
+		h.Write([]byte{0})
+	}
+}
+
+func (h inputHash) sum() string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// contextInputs inicia o hash de um documento com a configuração e a
+// estrutura de pacotes, presentes em todos os contextos. Com um template, as
+// estatísticas do projeto também podem aparecer em todos eles.
+func (g *Generator) contextInputs() inputHash {
+	h := newInputHash()
+	h.add(g.manifest.current.Settings, g.manifest.structure)
+	if g.config.Template != nil {
+		h.add(fmt.Sprintf("%+v", g.stats))
+	}
+	return h
+}
+
+// projectInputs resume as entradas dos documentos do projeto inteiro: todos
+// os arquivos com as dependências resolvidas, as exclusões e os problemas do
+// escaneamento, e as opções de exportação do grafo.
+func (g *Generator) projectInputs(files []*analyzer.GoFile) string {
+	sorted := append([]*analyzer.GoFile(nil), files...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Path < sorted[j].Path })
+
+	h := g.contextInputs()
+	for _, file := range sorted {
+		g.addFiles(h, file)
+		h.add(strconv.Itoa(len(file.Dependencies)))
+		h.add(file.Dependencies...)
+	}
+	for _, skipped := range g.skippedPaths {
+		h.add(skipped.Path, strconv.FormatBool(skipped.IsDir), skipped.Rule)
+	}
+	for _, problem := range g.diagnostics {
+		h.add(fmt.Sprintf("%+v", problem))
+	}
+	h.add(string(g.config.GraphLevel))
+	for _, format := range g.config.GraphFormats {
+		h.add(string(format))
+	}
+	return h.sum()
+}
+
+// addFiles acrescenta o caminho e o hash de cada arquivo.
+func (g *Generator) addFiles(h inputHash, files ...*analyzer.GoFile) {
+	for _, file := range files {
+		h.add(file.Path, g.manifest.sources[file.Path])
+	}
+}
+
+// addPackages acrescenta todos os arquivos dos pacotes informados. Com
+// SliceDependencies, as declarações mantidas de uma dependência dependem de
+// referências que podem passar por qualquer arquivo desses pacotes.
+func (g *Generator) addPackages(h inputHash, importPaths ...string) {
+	sort.Strings(importPaths)
+	for i, importPath := range importPaths {
+		if i > 0 && importPath == importPaths[i-1] {
+			continue
+		}
+		h.add(importPath)
+		g.addFiles(h, g.manifest.members[importPath]...)
+	}
+}
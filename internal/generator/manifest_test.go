@@ -0,0 +1,60 @@
+package generator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-context-generator/internal/testfixture"
+)
+
+// TestUnchangedRunWritesNothing exige que uma segunda geração sem alterações
+// não grave nenhum documento, nem os do projeto inteiro, e que uma alteração
+// volte a gravar a visão geral e as exportações.
+func TestUnchangedRunWritesNothing(t *testing.T) {
+	for _, mode := range []OutputMode{ModePerFile, ModePackages, ModeBundle} {
+		t.Run(string(mode), func(t *testing.T) {
+			src := t.TempDir()
+			testfixture.Write(t, src, 4, 2)
+
+			config := Config{
+				SourceDir:    src,
+				OutputDir:    t.TempDir(),
+				Mode:         mode,
+				ExportJSON:   true,
+				GraphFormats: []GraphFormat{GraphDOT, GraphMermaid, GraphJSON},
+			}
+			run := func() *Generator {
+				gen := NewGenerator(config)
+				if err := gen.GenerateContextFiles(context.Background(), scanFixture(t, src, 0)); err != nil {
+					t.Fatal(err)
+				}
+				return gen
+			}
+
+			first := run()
+			if first.Written() == 0 || first.Reused() != 0 {
+				t.Fatalf("primeira geração: %d gravados, %d mantidos", first.Written(), first.Reused())
+			}
+
+			if second := run(); second.Written() != 0 || second.Reused() != first.Written() {
+				t.Errorf("sem alterações: %d gravados, %d mantidos; esperados 0 e %d", second.Written(), second.Reused(), first.Written())
+			}
+
+			path := filepath.Join(src, "pkg", "p0", "f0.go")
+			f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.WriteString("\nconst Extra = 1\n")
+			f.Close()
+
+			// Visão geral (ou bundle), project.json, files.jsonl e os três
+			// grafos, mais os contextos afetados
+			if third := run(); third.Written() < 6 {
+				t.Errorf("depois de uma alteração: %d gravados, esperados ao menos 6", third.Written())
+			}
+		})
+	}
+}
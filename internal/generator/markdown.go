@@ -0,0 +1,322 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// markdownRenderer gera documentos Markdown: títulos, tabelas para as
+// estatísticas e blocos ```go para o código.
+type markdownRenderer struct{}
+
+func (markdownRenderer) Extension() string { return ".md" }
+
+func (r markdownRenderer) Overview(data *OverviewData) string {
+	var content strings.Builder
+	r.writeOverview(&content, data)
+	r.writeFooter(&content, "Generated by Go Context Generator Pro v2.0")
+	return content.String()
+}
+
+func (r markdownRenderer) Bundle(data *BundleData) string {
+	var content strings.Builder
+	r.writeOverview(&content, data.Overview)
+
+	content.WriteString("## Source Files (dependency order)\n\n")
+	total := len(data.Files)
+	for i, file := range data.Files {
+		content.WriteString(fmt.Sprintf("### %d/%d `%s`\n\n", i+1, total, file.Path))
+		content.WriteString(fmt.Sprintf("Package: `%s` · LOC: %d · Tokens: %d%s\n\n", file.Package, file.LOC, file.Tokens, fileNote(file, " · ")))
+		writeGoFence(&content, file.Content)
+	}
+
+	r.writeFooter(&content, "Generated by Go Context Generator Pro v2.0")
+	return content.String()
+}
+
+func (markdownRenderer) WithPrompt(document, prompt string, bottom bool) string {
+	task := "## Task\n\n" + prompt + "\n"
+	if bottom {
+		return strings.TrimRight(document, "\n") + "\n\n---\n\n" + task
+	}
+	return task + "\n---\n\n" + document
+}
+
+func (markdownRenderer) writeOverview(content *strings.Builder, data *OverviewData) {
+	content.WriteString("# Go Project Overview\n\n")
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("- **Generated:** %s\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString(fmt.Sprintf("- **Source Directory:** `%s`\n", data.SourceDir))
+	content.WriteString(fmt.Sprintf("- **Total Files Analyzed:** %d\n\n", data.Stats.TotalFiles))
+
+	stats := data.Stats
+	content.WriteString("## Project Statistics\n\n")
+	content.WriteString("| Metric | Value |\n|---|---|\n")
+	content.WriteString(fmt.Sprintf("| Total Go Files | %d |\n", stats.TotalFiles))
+	content.WriteString(fmt.Sprintf("| Total Packages | %d |\n", stats.TotalPackages))
+	content.WriteString(fmt.Sprintf("| Total Imports | %d |\n", stats.TotalImports))
+	content.WriteString(fmt.Sprintf("| Total Lines of Code | %d |\n", stats.TotalLOC))
+	content.WriteString(fmt.Sprintf("| Largest File | `%s` (%d bytes) |\n", markdownCell(stats.LargestFile), stats.LargestSize))
+	content.WriteString(fmt.Sprintf("| Total Tokens (%s) | %d |\n\n", markdownCell(stats.Tokenizer), stats.TotalTokens))
+
+	content.WriteString("## Package Structure\n\n")
+	for _, pkg := range data.Packages {
+		content.WriteString(fmt.Sprintf("### `%s` (%d files)\n\n", pkg.Name, len(pkg.Files)))
+		content.WriteString("| File | LOC | Tokens |\n|---|---:|---:|\n")
+		for _, file := range pkg.Files {
+			content.WriteString(fmt.Sprintf("| `%s` | %d | %d |\n", markdownCell(file.Path), file.LOC, file.Tokens))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(data.DependencyMap) > 0 {
+		content.WriteString("## Dependency Map\n\n")
+		for _, edge := range data.DependencyMap {
+			content.WriteString(fmt.Sprintf("- `%s`\n", edge.File))
+			for _, dep := range edge.Dependencies {
+				content.WriteString(fmt.Sprintf("  - → `%s`\n", dep))
+			}
+		}
+		content.WriteString("\n")
+	}
+
+	if data.Graph != nil && len(data.Graph.Edges) > 0 {
+		content.WriteString("## Dependency Graph\n\n")
+		if len(data.Graph.Cycles) > 0 {
+			content.WriteString("Import cycles are highlighted in red.\n\n")
+		}
+		content.WriteString("```mermaid\n" + data.Graph.Mermaid() + "```\n\n")
+	}
+
+	if len(data.TopImports) > 0 {
+		content.WriteString("## Top External Imports\n\n")
+		content.WriteString("| Import | Used by |\n|---|---:|\n")
+		for _, imp := range data.TopImports {
+			content.WriteString(fmt.Sprintf("| `%s` | %d |\n", markdownCell(imp.Path), imp.Count))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(data.Skipped) > 0 {
+		content.WriteString("## Skipped Paths\n\n")
+		content.WriteString("| Path | Rule |\n|---|---|\n")
+		for _, skipped := range data.Skipped {
+			path := skipped.Path
+			if skipped.IsDir {
+				path += "/"
+			}
+			content.WriteString(fmt.Sprintf("| `%s` | `%s` |\n", markdownCell(path), markdownCell(skipped.Rule)))
+		}
+		content.WriteString("\n")
+	}
+
+	if len(data.Problems) > 0 {
+		content.WriteString("## Problems\n\n")
+		for _, problem := range data.Problems {
+			content.WriteString(fmt.Sprintf("- `%s`: %s%s\n", problem.Position(), problem.Reason, problemNote(problem)))
+			for _, err := range problem.Errors {
+				content.WriteString(fmt.Sprintf("  - `%s`\n", err))
+			}
+		}
+		content.WriteString("\n")
+	}
+}
+
+func (r markdownRenderer) Context(data *ContextData) string {
+	var content strings.Builder
+
+	content.WriteString(fmt.Sprintf("# AI Context: `%s`\n\n", data.File.Path))
+
+	content.WriteString("## File Metadata\n\n")
+	content.WriteString("| Field | Value |\n|---|---|\n")
+	content.WriteString(fmt.Sprintf("| File | `%s` |\n", markdownCell(data.File.Path)))
+	content.WriteString(fmt.Sprintf("| Package | `%s` |\n", data.File.Package))
+	content.WriteString(fmt.Sprintf("| Lines of Code | %d |\n", data.File.LOC))
+	content.WriteString(fmt.Sprintf("| Tokens (%s) | %d |\n", markdownCell(data.Tokenizer), data.Tokens))
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("| Generated | %s |\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString("\n")
+
+	imports := data.Imports
+	if len(imports.Std)+len(imports.External)+len(imports.Local) > 0 {
+		content.WriteString("## Imports\n\n")
+		writeImportList(&content, "Standard Library", imports.Std)
+		writeImportList(&content, "External Packages", imports.External)
+		writeImportList(&content, "Local Project", imports.Local)
+	}
+
+	content.WriteString("## Project Context\n\n")
+	for _, pkg := range data.Structure {
+		files := make([]string, 0, len(pkg.Files))
+		for _, file := range pkg.Files {
+			files = append(files, "`"+filepath.Base(file.Path)+"`")
+		}
+		if len(files) > 3 {
+			files = append(files[:3], fmt.Sprintf("… (+%d more)", len(pkg.Files)-3))
+		}
+		content.WriteString(fmt.Sprintf("- **%s:** %s\n", pkg.Name, strings.Join(files, ", ")))
+	}
+	content.WriteString("\n")
+
+	content.WriteString("## Source Code" + fileNote(data.File, " — ") + "\n\n")
+	writeGoFence(&content, data.File.Content)
+
+	if len(data.Dependencies) > 0 {
+		content.WriteString("## Related Code\n\n")
+		for i, dep := range data.Dependencies {
+			content.WriteString(fmt.Sprintf("### Dependency %d: `%s`\n\n", i+1, dep.Path))
+			content.WriteString(fmt.Sprintf("Package: `%s` · LOC: %d · Tokens: %d · Via: %s%s\n\n", dep.Package, dep.LOC, dep.Tokens, formatChain(dep.Chain), fileNote(dep.FileData, " · ")))
+			writeGoFence(&content, dep.Content)
+		}
+	}
+
+	if len(data.Omitted) > 0 {
+		content.WriteString("## Omitted Dependencies (token budget)\n\n")
+		for _, dep := range data.Omitted {
+			content.WriteString(fmt.Sprintf("- `%s` (%d tokens, via %s)\n", dep.Path, dep.Tokens, formatChain(dep.Chain)))
+		}
+		content.WriteString("\n")
+	}
+
+	writeCycleList(&content, data.Cycles)
+
+	r.writeFooter(&content, "AI-optimized context · tokens minimized for efficient processing")
+	return content.String()
+}
+
+func (r markdownRenderer) Package(data *PackageContextData) string {
+	var content strings.Builder
+
+	content.WriteString(fmt.Sprintf("# AI Package Context: `%s`\n\n", data.ImportPath))
+
+	content.WriteString("## Package Metadata\n\n")
+	content.WriteString("| Field | Value |\n|---|---|\n")
+	content.WriteString(fmt.Sprintf("| Import Path | `%s` |\n", markdownCell(data.ImportPath)))
+	content.WriteString(fmt.Sprintf("| Package | `%s` |\n", data.Name))
+	content.WriteString(fmt.Sprintf("| Directory | `%s` |\n", markdownCell(data.Dir)))
+	content.WriteString(fmt.Sprintf("| Files | %d |\n", len(data.Files)))
+	content.WriteString(fmt.Sprintf("| Tokens (%s) | %d |\n", markdownCell(data.Tokenizer), data.Tokens))
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("| Generated | %s |\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString("\n")
+
+	imports := data.Imports
+	if len(imports.Std)+len(imports.External)+len(imports.Local) > 0 {
+		content.WriteString("## Imports\n\n")
+		writeImportList(&content, "Standard Library", imports.Std)
+		writeImportList(&content, "External Packages", imports.External)
+		writeImportList(&content, "Local Project", imports.Local)
+	}
+
+	content.WriteString("## Package Source\n\n")
+	for _, file := range data.Files {
+		content.WriteString(fmt.Sprintf("### `%s`\n\n", file.Path))
+		content.WriteString(fmt.Sprintf("LOC: %d · Tokens: %d%s\n\n", file.LOC, file.Tokens, fileNote(file, " · ")))
+		writeGoFence(&content, file.Content)
+	}
+
+	if len(data.Dependencies) > 0 {
+		content.WriteString("## Imported Packages\n\n")
+		for _, dep := range data.Dependencies {
+			content.WriteString(fmt.Sprintf("### `%s`\n\n", dep.ImportPath))
+			content.WriteString(fmt.Sprintf("Via: %s\n\n", formatChain(dep.Chain)))
+			for _, file := range dep.Files {
+				content.WriteString(fmt.Sprintf("#### `%s`\n\n", file.Path))
+				content.WriteString(fmt.Sprintf("LOC: %d · Tokens: %d%s\n\n", file.LOC, file.Tokens, fileNote(file, " · ")))
+				writeGoFence(&content, file.Content)
+			}
+		}
+	}
+
+	if len(data.Importers) > 0 {
+		content.WriteString("## Imported By (exported API)\n\n")
+		for _, importer := range data.Importers {
+			content.WriteString(fmt.Sprintf("### `%s`\n\n", importer.ImportPath))
+			if importer.API != "" {
+				writeGoFence(&content, importer.API)
+			} else {
+				content.WriteString("_No exported declarations._\n\n")
+			}
+		}
+	}
+
+	if len(data.Omitted) > 0 {
+		content.WriteString("## Omitted Packages (token budget)\n\n")
+		for _, dep := range data.Omitted {
+			content.WriteString(fmt.Sprintf("- `%s` (%d tokens, via %s)\n", dep.ImportPath, dep.Tokens, formatChain(dep.Chain)))
+		}
+		content.WriteString("\n")
+	}
+
+	writeCycleList(&content, data.Cycles)
+
+	r.writeFooter(&content, "AI-optimized context · tokens minimized for efficient processing")
+	return content.String()
+}
+
+func (markdownRenderer) writeFooter(content *strings.Builder, text string) {
+	content.WriteString("---\n\n")
+	content.WriteString("_" + text + "_\n")
+}
+
+func writeCycleList(content *strings.Builder, cycles [][]string) {
+	if len(cycles) == 0 {
+		return
+	}
+	content.WriteString("## Import Cycles\n\n")
+	for _, cycle := range cycles {
+		content.WriteString(fmt.Sprintf("- %s\n", formatChain(cycle)))
+	}
+	content.WriteString("\n")
+}
+
+func writeImportList(content *strings.Builder, title string, imports []string) {
+	if len(imports) == 0 {
+		return
+	}
+	content.WriteString(fmt.Sprintf("**%s:**\n\n", title))
+	for _, imp := range imports {
+		content.WriteString(fmt.Sprintf("- `%s`\n", imp))
+	}
+	content.WriteString("\n")
+}
+
+// writeGoFence escreve o código em um bloco ```go. A cerca usa sempre mais
+// crases do que a maior sequência presente no código (ex.: raw strings).
+func writeGoFence(content *strings.Builder, code string) {
+	fence := codeFence(code)
+	content.WriteString(fence + "go\n")
+	content.WriteString(code)
+	if !strings.HasSuffix(code, "\n") {
+		content.WriteString("\n")
+	}
+	content.WriteString(fence + "\n\n")
+}
+
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for _, c := range code {
+		if c == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// markdownCell escapa barras verticais para não quebrar tabelas.
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,116 @@
+package generator
+
+import (
+	"time"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// FileData descreve um arquivo Go já limpo, como aparece nos documentos gerados.
+type FileData struct {
+	Path     string // Relativo à pasta de origem
+	Name     string
+	Package  string
+	LOC      int
+	Size     int64
+	Tokens   int    // Tokens do código limpo
+	Content  string // Código limpo (CleanContent), recortado ou esqueleto
+	Skeleton bool   // Content sem os corpos de funções e métodos
+
+	Unparseable bool // Content é o código original de um arquivo com erro de sintaxe
+}
+
+// PackageData agrupa os arquivos de um pacote.
+type PackageData struct {
+	Name  string
+	Files []FileData
+}
+
+// DependencyEdge liga um arquivo às dependências locais que ele importa.
+type DependencyEdge struct {
+	File         string
+	Dependencies []string
+}
+
+// ImportUsage conta quantos arquivos usam um import externo.
+type ImportUsage struct {
+	Path  string
+	Count int
+}
+
+// ImportGroups separa os imports de um arquivo por origem.
+type ImportGroups struct {
+	Std      []string
+	External []string
+	Local    []string
+}
+
+// OverviewData alimenta a visão geral do projeto.
+type OverviewData struct {
+	SourceDir     string
+	Generated     time.Time
+	Stats         ProjectStats
+	Packages      []PackageData
+	DependencyMap []DependencyEdge
+	Graph         *DependencyGraph // Apenas com a exportação Mermaid ativa
+	TopImports    []ImportUsage
+	Skipped       []analyzer.SkippedPath
+	Problems      []analyzer.Diagnostic
+}
+
+// ContextData alimenta o contexto de um arquivo: o código principal, o código
+// das dependências locais e as dependências descartadas pelo orçamento.
+type ContextData struct {
+	File         FileData
+	Imports      ImportGroups
+	Structure    []PackageData
+	Stats        ProjectStats // Usado apenas por templates (ver Template)
+	Dependencies []DependencyData
+	Omitted      []DependencyData
+	Cycles       [][]string // Ciclos de import encontrados, em nomes de pacote
+	Tokens       int        // Tokens do documento renderizado
+	Tokenizer    string
+	Generated    time.Time
+}
+
+// DependencyData é o código de uma dependência local e a cadeia de imports
+// que a trouxe para o contexto.
+type DependencyData struct {
+	FileData
+	Chain []string // Pacotes do arquivo principal até a dependência, ex.: handlers → service → repo
+	Depth int      // 1 = import direto
+}
+
+// BundleData alimenta o documento único do modo bundle.
+type BundleData struct {
+	Overview *OverviewData
+	Files    []FileData // Em ordem de dependência
+}
+
+// PackageContextData alimenta o contexto de um pacote no modo packages.
+type PackageContextData struct {
+	ImportPath   string
+	Name         string
+	Dir          string     // Relativo à pasta de origem
+	Files        []FileData // Todos os arquivos do pacote
+	Imports      ImportGroups
+	Structure    []PackageData
+	Stats        ProjectStats // Usado apenas por templates (ver Template)
+	Dependencies []PackageRef // Pacotes locais importados, com o código completo
+	Importers    []PackageRef // Pacotes locais que importam este, apenas a API
+	Omitted      []PackageRef // Pacotes importados descartados pelo orçamento
+	Cycles       [][]string   // Ciclos de import encontrados, em nomes de pacote
+	Tokens       int
+	Tokenizer    string
+	Generated    time.Time
+}
+
+// PackageRef descreve outro pacote local citado em um contexto de pacote.
+type PackageRef struct {
+	ImportPath string
+	Name       string
+	Files      []FileData // Preenchido para dependências
+	API        string     // Declarações exportadas, preenchido para importadores
+	Tokens     int        // Tokens do código limpo de todos os arquivos
+	Chain      []string   // Cadeia de imports até o pacote (dependências)
+}
@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// NamingScheme define como o caminho de origem de um contexto vira o nome do
+// documento. O manifesto registra a origem de cada documento (ver
+// ManifestFile), e uma colisão interrompe a geração antes da gravação.
+type NamingScheme string
+
+const (
+	// NamingFlat grava os contextos na raiz da pasta de saída, com o caminho
+	// achatado e um hash curto do caminho original, que separa caminhos como
+	// a/b_c.go e a_b/c.go: internal/ui/app.go vira
+	// internal_ui_app-13d6b02c_CONTEXT.txt (padrão).
+	NamingFlat NamingScheme = "flat"
+	// NamingMirror reproduz a estrutura de diretórios da origem:
+	// internal/ui/app.go vira internal/ui/app_CONTEXT.txt e o pacote em
+	// internal/ui vira internal/ui/ui_PACKAGE_CONTEXT.txt.
+	NamingMirror NamingScheme = "mirror"
+)
+
+// ParseNamingScheme converte o nome de um esquema; vazio resulta em NamingFlat.
+func ParseNamingScheme(name string) (NamingScheme, error) {
+	switch NamingScheme(strings.ToLower(strings.TrimSpace(name))) {
+	case "", NamingFlat:
+		return NamingFlat, nil
+	case NamingMirror, "tree", "dirs":
+		return NamingMirror, nil
+	}
+	return "", fmt.Errorf("esquema de nomes desconhecido: %q", name)
+}
+
+// fileOutputName nomeia o contexto de um arquivo a partir do caminho relativo
+// à origem, com barras. O nome retornado também usa barras.
+func (g *Generator) fileOutputName(relPath string) string {
+	base := strings.TrimSuffix(relPath, ".go")
+	suffix := "_CONTEXT" + g.renderer.Extension()
+	if g.config.Naming == NamingMirror {
+		return base + suffix
+	}
+	return flatName(base, relPath) + suffix
+}
+
+// packageOutputName nomeia o contexto de um pacote: pelo diretório no
+// esquema espelhado, pelo import path no achatado.
+func (g *Generator) packageOutputName(group *packageGroup) string {
+	suffix := "_PACKAGE_CONTEXT" + g.renderer.Extension()
+	if g.config.Naming == NamingMirror {
+		return path.Join(filepath.ToSlash(group.dir), group.name+suffix)
+	}
+	return flatName(group.importPath, group.importPath) + suffix
+}
+
+// flatName achata name, trocando separadores por "_", e acrescenta os
+// primeiros 8 dígitos do SHA-256 de key.
+func flatName(name, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	flat := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(name)
+	return flat + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,266 @@
+package generator
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/parallel"
+)
+
+// packageGroup reúne os arquivos de um diretório, a unidade do modo packages.
+type packageGroup struct {
+	importPath string
+	name       string
+	dir        string // Relativo à pasta de origem
+	files      []*analyzer.GoFile
+	imports    map[string]bool // União dos imports dos arquivos
+}
+
+// groupPackages agrupa os arquivos por diretório, em ordem de import path.
+func (g *Generator) groupPackages(files []*analyzer.GoFile) []*packageGroup {
+	module := g.getProjectModule()
+	groups := make(map[string]*packageGroup)
+
+	for _, file := range files {
+		dir := filepath.Dir(g.relPath(file.Path))
+		group, exists := groups[dir]
+		if !exists {
+			importPath := file.ImportPath
+			if importPath == "" {
+				importPath = module
+				if dir != "." {
+					importPath = module + "/" + filepath.ToSlash(dir)
+				}
+			}
+			group = &packageGroup{importPath: importPath, dir: dir, imports: make(map[string]bool)}
+			groups[dir] = group
+		}
+
+		group.files = append(group.files, file)
+		// Pacotes externos de teste (foo_test) não dão nome ao diretório
+		if group.name == "" || (strings.HasSuffix(group.name, "_test") && !strings.HasSuffix(file.Package, "_test")) {
+			group.name = file.Package
+		}
+		for _, imp := range file.Imports {
+			group.imports[imp] = true
+		}
+	}
+
+	sorted := make([]*packageGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Slice(group.files, func(i, j int) bool { return group.files[i].Path < group.files[j].Path })
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].importPath < sorted[j].importPath })
+
+	return sorted
+}
+
+// generatePackageContexts escreve a visão geral e um contexto por pacote, com
+// o código de todos os seus arquivos, o código dos pacotes locais que ele
+// importa e a API exportada dos pacotes que o importam.
+func (g *Generator) generatePackageContexts(ctx context.Context, files []*analyzer.GoFile) error {
+	if err := g.generateProjectOverview(files); err != nil {
+		return err
+	}
+
+	groups := g.groupPackages(files)
+
+	progress := parallel.NewProgress(len(groups), g.progressCallback)
+	progress.Start()
+
+	return parallel.ForEach(ctx, len(groups), g.config.Workers, func(i int) error {
+		group := groups[i]
+
+		var importers []*packageGroup
+		for _, other := range groups {
+			if other != group && other.imports[group.importPath] {
+				importers = append(importers, other)
+			}
+		}
+
+		deps, cycles := walkDependencies(group, func(pkg *packageGroup) []*packageGroup {
+			var direct []*packageGroup
+			for _, other := range groups {
+				if other != pkg && pkg.imports[other.importPath] {
+					direct = append(direct, other)
+				}
+			}
+			return direct
+		}, g.config.DependencyDepth)
+
+		if err := g.generatePackageContext(group, deps, importers, cycles); err != nil {
+			return err
+		}
+		progress.Done()
+		return nil
+	})
+}
+
+func (g *Generator) generatePackageContext(group *packageGroup, deps []dependencyChain[*packageGroup], importers []*packageGroup, cycles []dependencyChain[*packageGroup]) error {
+	outputName := g.packageOutputName(group)
+	outputPath := g.outputPath(outputName)
+
+	// Fora das alterações informadas, manter o contexto anterior; a API de
+	// quem importa o pacote também aparece nele
+	related := group.files
+	for _, importer := range importers {
+		related = append(related[:len(related):len(related)], importer.files...)
+	}
+	if g.unaffected(related...) && g.manifest.carry(g.config.OutputDir, outputName, group.importPath) {
+		return nil
+	}
+
+	// Manter o contexto anterior se o pacote, os pacotes relacionados e a
+	// configuração não mudaram
+	inputs := g.contextInputs()
+	g.addFiles(inputs, group.files...)
+	sliced := []string{group.importPath}
+	for _, dep := range deps {
+		inputs.add(groupNames(dep)...)
+		g.addFiles(inputs, dep.last().files...)
+		sliced = append(sliced, dep.last().importPath)
+	}
+	for _, importer := range importers {
+		inputs.add(importer.importPath)
+		g.addFiles(inputs, importer.files...)
+	}
+	for _, cycle := range cycles {
+		inputs.add(groupNames(cycle)...)
+	}
+	if g.config.SliceDependencies {
+		g.addPackages(inputs, sliced...)
+	}
+	if g.manifest.upToDate(g.config.OutputDir, outputName, group.importPath, inputs.sum()) {
+		return nil
+	}
+
+	view := g.newDependencyView(group.files...)
+	var omitted []dependencyChain[*packageGroup]
+	data := g.buildPackageContext(group, view, deps, importers, omitted, cycles)
+	tokens := g.countTokens(g.renderer.Package(data))
+	if budget := g.config.MaxTokensPerFile; budget > 0 && tokens > budget {
+		deps, omitted = g.trimPackages(view, deps, tokens-budget)
+		data = g.buildPackageContext(group, view, deps, importers, omitted, cycles)
+		tokens = g.countTokens(g.renderer.Package(data))
+	}
+
+	data.Tokens = tokens
+	return writeOutput(outputPath, []byte(g.renderer.Package(data)))
+}
+
+func (g *Generator) buildPackageContext(group *packageGroup, view *dependencyView, deps []dependencyChain[*packageGroup], importers []*packageGroup, omitted, cycles []dependencyChain[*packageGroup]) *PackageContextData {
+	data := &PackageContextData{
+		ImportPath: group.importPath,
+		Name:       group.name,
+		Dir:        group.dir,
+		Structure:  g.packages,
+		Stats:      g.stats,
+		Tokenizer:  g.config.Tokenizer.Name(),
+		Generated:  g.generated,
+	}
+
+	var imports []string
+	for imp := range group.imports {
+		if imp != group.importPath {
+			imports = append(imports, imp)
+		}
+	}
+	data.Imports.Std, data.Imports.External, data.Imports.Local = g.categorizeImports(imports)
+
+	for _, file := range group.files {
+		data.Files = append(data.Files, g.mainFileData(file))
+	}
+	for _, dep := range deps {
+		ref := g.packageRef(dep.last(), view)
+		ref.Chain = groupNames(dep)
+		data.Dependencies = append(data.Dependencies, ref)
+	}
+	for _, importer := range importers {
+		data.Importers = append(data.Importers, g.packageRef(importer, nil))
+	}
+	for _, dep := range omitted {
+		ref := g.packageRef(dep.last(), nil)
+		ref.Chain = groupNames(dep)
+		data.Omitted = append(data.Omitted, ref)
+	}
+	for _, cycle := range cycles {
+		data.Cycles = append(data.Cycles, groupNames(cycle))
+	}
+
+	return data
+}
+
+// packageRef descreve outro pacote: com o código dos arquivos, conforme a
+// view, ou apenas com a API exportada quando view é nil.
+func (g *Generator) packageRef(group *packageGroup, view *dependencyView) PackageRef {
+	ref := PackageRef{ImportPath: group.importPath, Name: group.name}
+
+	var api []string
+	for _, file := range group.files {
+		if view != nil {
+			data := view.fileData(file)
+			ref.Files = append(ref.Files, data)
+			ref.Tokens += data.Tokens
+			continue
+		}
+
+		ref.Tokens += g.fileTokens[file.Path]
+		if file.API != "" && !strings.HasSuffix(file.Name, "_test.go") {
+			api = append(api, file.API)
+		}
+	}
+	ref.API = strings.Join(api, "\n\n")
+
+	return ref
+}
+
+func groupNames(chain dependencyChain[*packageGroup]) []string {
+	names := make([]string, len(chain))
+	for i, group := range chain {
+		names[i] = group.name
+	}
+	return names
+}
+
+// trimPackages descarta pacotes importados, dos mais distantes para os mais
+// próximos e dos maiores para os menores, até economizar pelo menos excess tokens.
+func (g *Generator) trimPackages(view *dependencyView, deps []dependencyChain[*packageGroup], excess int) (kept, omitted []dependencyChain[*packageGroup]) {
+	tokens := func(group *packageGroup) int {
+		total := 0
+		for _, file := range group.files {
+			total += view.tokens(file) + dependencyHeaderTokens
+		}
+		return total
+	}
+
+	candidates := append([]dependencyChain[*packageGroup]{}, deps...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.depth() != b.depth() {
+			return a.depth() > b.depth()
+		}
+		return tokens(a.last()) > tokens(b.last())
+	})
+
+	dropped := make(map[*packageGroup]bool)
+	for _, dep := range candidates {
+		if excess <= 0 {
+			break
+		}
+		dropped[dep.last()] = true
+		omitted = append(omitted, dep)
+		excess -= tokens(dep.last())
+	}
+
+	for _, dep := range deps {
+		if !dropped[dep.last()] {
+			kept = append(kept, dep)
+		}
+	}
+
+	return kept, omitted
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"go-context-generator/internal/prompt"
+)
+
+// PromptPosition define onde o prompt de tarefa entra nos contextos.
+type PromptPosition string
+
+const (
+	// PromptTop insere o prompt antes do contexto (padrão).
+	PromptTop PromptPosition = "top"
+	// PromptBottom insere o prompt depois do contexto, logo antes da pergunta.
+	PromptBottom PromptPosition = "bottom"
+)
+
+// ParsePromptPosition converte o nome de uma posição; vazio resulta em PromptTop.
+func ParsePromptPosition(name string) (PromptPosition, error) {
+	switch PromptPosition(strings.ToLower(strings.TrimSpace(name))) {
+	case "", PromptTop:
+		return PromptTop, nil
+	case PromptBottom:
+		return PromptBottom, nil
+	}
+	return "", fmt.Errorf("posição do prompt desconhecida: %q", name)
+}
+
+// promptRenderer insere o prompt em cada contexto (de arquivo, de pacote e
+// o bundle); a visão geral fica como está. O formato decide como o prompt
+// aparece (ver renderer.WithPrompt). Erros de execução seguem o mesmo
+// caminho dos templates.
+type promptRenderer struct {
+	renderer
+	prompt *prompt.Prompt
+	bottom bool
+	mu     sync.Mutex
+	err    error
+}
+
+func (r *promptRenderer) Context(data *ContextData) string {
+	deps := make(prompt.List, 0, len(data.Dependencies))
+	for _, dep := range data.Dependencies {
+		deps = append(deps, dep.Path)
+	}
+	return r.inject(r.renderer.Context(data), prompt.Data{
+		Target:       data.File.Path,
+		File:         data.File.Path,
+		Package:      data.File.Package,
+		Dependencies: deps,
+	})
+}
+
+func (r *promptRenderer) Package(data *PackageContextData) string {
+	deps := make(prompt.List, 0, len(data.Dependencies))
+	for _, dep := range data.Dependencies {
+		deps = append(deps, dep.ImportPath)
+	}
+	return r.inject(r.renderer.Package(data), prompt.Data{
+		Target:       "o pacote " + data.ImportPath,
+		Package:      data.Name,
+		ImportPath:   data.ImportPath,
+		Dependencies: deps,
+	})
+}
+
+func (r *promptRenderer) Bundle(data *BundleData) string {
+	return r.inject(r.renderer.Bundle(data), prompt.Data{Target: "o projeto"})
+}
+
+func (r *promptRenderer) inject(document string, data prompt.Data) string {
+	text, err := r.prompt.Render(data)
+	if err != nil {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = err
+		}
+		r.mu.Unlock()
+		return document
+	}
+	return r.renderer.WithPrompt(document, text, r.bottom)
+}
+
+// Err retorna o primeiro erro do prompt ou do renderer envolvido.
+func (r *promptRenderer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	if inner, ok := r.renderer.(interface{ Err() error }); ok {
+		return inner.Err()
+	}
+	return nil
+}
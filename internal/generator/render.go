@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// Format define a linguagem dos documentos gerados.
+type Format string
+
+const (
+	// FormatText é o layout em texto puro com cabeçalhos decorados (padrão).
+	FormatText Format = "text"
+	// FormatMarkdown usa títulos, tabelas e blocos ```go.
+	FormatMarkdown Format = "markdown"
+	// FormatXML delimita cada parte com tags (<project_overview>, <file>,
+	// <dependency>...) e o código com CDATA.
+	FormatXML Format = "xml"
+)
+
+// ParseFormat converte o nome de um formato; vazio resulta em FormatText.
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(name))) {
+	case "", FormatText, "txt":
+		return FormatText, nil
+	case FormatMarkdown, "md":
+		return FormatMarkdown, nil
+	case FormatXML:
+		return FormatXML, nil
+	}
+	return "", fmt.Errorf("formato de saída desconhecido: %q", name)
+}
+
+// renderer transforma os dados já calculados pelo Generator em documentos.
+// Implementações não fazem I/O nem dependem de estado do Generator.
+type renderer interface {
+	// Extension retorna a extensão dos arquivos gerados, com o ponto.
+	Extension() string
+	Overview(data *OverviewData) string
+	Context(data *ContextData) string
+	Package(data *PackageContextData) string
+	Bundle(data *BundleData) string
+	// WithPrompt adiciona o prompt de tarefa no início do documento, ou no
+	// fim se bottom for true.
+	WithPrompt(document, prompt string, bottom bool) string
+}
+
+func newRenderer(format Format) renderer {
+	switch format {
+	case FormatMarkdown:
+		return markdownRenderer{}
+	case FormatXML:
+		return xmlRenderer{}
+	default:
+		return textRenderer{}
+	}
+}
+
+// fileNote marca nos cabeçalhos o código mostrado sem corpos de funções e o
+// código bruto de arquivos que não puderam ser interpretados.
+func fileNote(file FileData, separator string) string {
+	switch {
+	case file.Unparseable:
+		return separator + "unparseable, raw source"
+	case file.Skeleton:
+		return separator + "skeleton"
+	}
+	return ""
+}
+
+// problemNote indica se o arquivo com problema foi incluído como código bruto.
+func problemNote(problem analyzer.Diagnostic) string {
+	if problem.Included {
+		return " (included as raw source)"
+	}
+	return " (excluded)"
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+
+	"go-context-generator/internal/analyzer"
+)
+
+// dependencyView decide como as dependências aparecem em um contexto: com o
+// código limpo completo ou, com SliceDependencies, apenas com as declarações
+// alcançáveis a partir dos arquivos principais; em ambos os casos, como
+// esqueleto se DependencyView for ViewSkeleton.
+type dependencyView struct {
+	g        *Generator
+	kept     map[*analyzer.Declaration]bool // nil = sem recorte
+	skeleton bool
+	cache    map[string]FileData // Por caminho
+}
+
+func (g *Generator) newDependencyView(roots ...*analyzer.GoFile) *dependencyView {
+	view := &dependencyView{
+		g:        g,
+		skeleton: g.config.DependencyView == ViewSkeleton,
+		cache:    make(map[string]FileData),
+	}
+	if g.config.SliceDependencies {
+		view.kept = referencedDeclarations(roots)
+	}
+	return view
+}
+
+// fileData descreve uma dependência, com Content e Tokens já recortados.
+func (v *dependencyView) fileData(file *analyzer.GoFile) FileData {
+	if cached, ok := v.cache[file.Path]; ok {
+		return cached
+	}
+
+	data := v.g.fileData(file)
+	switch {
+	case v.kept != nil && (file.Header != "" || file.Declarations != nil):
+		data.Content = slicedContent(file, v.kept, v.skeleton)
+		data.Skeleton = v.skeleton
+	case v.skeleton && file.Skeleton != "":
+		data.Content = file.Skeleton
+		data.Skeleton = true
+	default:
+		return data
+	}
+
+	data.Tokens = v.g.countTokens(data.Content)
+	v.cache[file.Path] = data
+	return data
+}
+
+func (v *dependencyView) tokens(file *analyzer.GoFile) int {
+	return v.fileData(file).Tokens
+}
+
+// referencedDeclarations segue Uses recursivamente a partir das declarações
+// dos arquivos principais.
+func referencedDeclarations(roots []*analyzer.GoFile) map[*analyzer.Declaration]bool {
+	kept := make(map[*analyzer.Declaration]bool)
+
+	var pending []*analyzer.Declaration
+	for _, file := range roots {
+		for _, decl := range file.Declarations {
+			pending = append(pending, decl.Uses...)
+		}
+	}
+
+	for len(pending) > 0 {
+		decl := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if kept[decl] {
+			continue
+		}
+		kept[decl] = true
+		pending = append(pending, decl.Uses...)
+	}
+
+	return kept
+}
+
+// slicedContent monta o arquivo com a cláusula package, os imports e as
+// declarações mantidas (completas ou como esqueleto), na ordem original,
+// seguidos de um marcador com o número de declarações omitidas.
+func slicedContent(file *analyzer.GoFile, kept map[*analyzer.Declaration]bool, skeleton bool) string {
+	parts := []string{file.Header}
+	omitted := 0
+
+	for _, decl := range file.Declarations {
+		switch {
+		case kept[decl] && skeleton:
+			parts = append(parts, decl.Skeleton)
+		case kept[decl]:
+			parts = append(parts, decl.Code)
+		default:
+			omitted++
+		}
+	}
+
+	switch omitted {
+	case 0:
+	case 1:
+		parts = append(parts, "// ... 1 declaration omitted")
+	default:
+		parts = append(parts, fmt.Sprintf("// ... %d declarations omitted", omitted))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// stagingPrefix nomeia a pasta de preparação, criada dentro da pasta de saída
+// para que o rename final não atravesse sistemas de arquivos.
+const stagingPrefix = ".staging-"
+
+// backupDir guarda, dentro da pasta de preparação, os documentos substituídos
+// durante commitOutputs, até que todos tenham sido movidos.
+const backupDir = ".previous"
+
+// staleAfter é o tempo sem nenhuma gravação depois do qual a preparação (ou o
+// manifesto temporário) de outra execução é considerada abandonada. Gerações
+// simultâneas na mesma pasta de saída, como o modo observação da interface e
+// uma execução da CLI, gravam nas próprias preparações bem antes disso.
+const staleAfter = time.Hour
+
+// prepareOutputDir cria a pasta de saída e a pasta de preparação onde a
+// geração grava os documentos, apagando as preparações abandonadas por
+// execuções interrompidas (ver staleAfter). Com Prune, recusa pastas que não
+// parecem uma saída anterior.
+func (g *Generator) prepareOutputDir() error {
+	if err := os.MkdirAll(g.config.OutputDir, 0755); err != nil {
+		return fmt.Errorf("erro ao criar diretório de saída: %w", err)
+	}
+
+	entries, err := os.ReadDir(g.config.OutputDir)
+	if err != nil {
+		return fmt.Errorf("erro ao ler diretório de saída: %w", err)
+	}
+
+	hasManifest := false
+	var foreign string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case entry.IsDir() && strings.HasPrefix(name, stagingPrefix):
+			path := filepath.Join(g.config.OutputDir, name)
+			if !abandoned(path) {
+				continue
+			}
+			if err := os.RemoveAll(path); err != nil {
+				return fmt.Errorf("erro ao apagar preparação anterior: %w", err)
+			}
+		case strings.HasPrefix(name, "."+ManifestFile+".") && strings.HasSuffix(name, ".tmp"):
+			if path := filepath.Join(g.config.OutputDir, name); abandoned(path) {
+				os.Remove(path)
+			}
+		case name == ManifestFile:
+			hasManifest = true
+		case foreign == "" && (entry.IsDir() || !generatedName(name)):
+			foreign = name
+		}
+	}
+	if g.config.Prune && !hasManifest && foreign != "" {
+		return fmt.Errorf("a pasta de saída %s não parece uma geração anterior (%s não foi gerado e não há %s); escolha outra pasta ou desative a limpeza de contextos antigos",
+			g.config.OutputDir, foreign, ManifestFile)
+	}
+
+	stageDir, err := os.MkdirTemp(g.config.OutputDir, stagingPrefix)
+	if err != nil {
+		return fmt.Errorf("erro ao criar diretório de preparação: %w", err)
+	}
+	g.stageDir = stageDir
+	return nil
+}
+
+// abandoned informa se nada foi gravado em path, ou dentro dele, há mais de
+// staleAfter.
+func abandoned(path string) bool {
+	var latest time.Time
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Removido enquanto o percurso acontecia
+		}
+		if info, err := d.Info(); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+		return nil
+	})
+	return !latest.IsZero() && time.Since(latest) > staleAfter
+}
+
+// outputPath retorna onde gravar um documento durante a geração.
+func (g *Generator) outputPath(name string) string {
+	return filepath.Join(g.stageDir, filepath.FromSlash(name))
+}
+
+// commitOutputs move os documentos preparados para a pasta de saída, só
+// depois que todos foram gerados. Cada rename substitui um arquivo inteiro;
+// se algum falhar, os já movidos são desfeitos (os substituídos voltam de
+// backupDir e os novos são apagados), então a pasta de saída fica como
+// estava. Só uma falha também na restauração deixa a pasta misturada, e o
+// erro retornado informa isso.
+func (g *Generator) commitOutputs() error {
+	var staged []string
+	err := filepath.WalkDir(g.stageDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(g.stageDir, path)
+		if err != nil {
+			return err
+		}
+		staged = append(staged, rel)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var moved []committedOutput
+	for _, rel := range staged {
+		output, err := g.commitOutput(rel)
+		if output.rel != "" {
+			moved = append(moved, output)
+		}
+		if err != nil {
+			if restoreErr := g.rollbackOutputs(moved); restoreErr != nil {
+				return fmt.Errorf("%w; a pasta de saída não pôde ser restaurada: %v", err, restoreErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// committedOutput é um documento movido (ou em movimento) para a saída.
+type committedOutput struct {
+	rel      string
+	replaced bool // A versão anterior está em backupDir
+}
+
+// commitOutput guarda a versão anterior do documento, se houver, e move a
+// nova para a pasta de saída. Um rel vazio indica que nada mudou na saída.
+func (g *Generator) commitOutput(rel string) (committedOutput, error) {
+	target := filepath.Join(g.config.OutputDir, rel)
+	output := committedOutput{rel: rel}
+
+	// Só arquivos são guardados: um diretório no lugar do documento faz o
+	// rename falhar, como antes
+	if info, err := os.Lstat(target); err == nil && !info.IsDir() {
+		backup := filepath.Join(g.stageDir, backupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(backup), 0755); err != nil {
+			return committedOutput{}, err
+		}
+		if err := os.Rename(target, backup); err != nil {
+			return committedOutput{}, err
+		}
+		output.replaced = true
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return output, err
+	}
+	return output, os.Rename(filepath.Join(g.stageDir, rel), target)
+}
+
+// rollbackOutputs desfaz os documentos movidos, do último para o primeiro.
+func (g *Generator) rollbackOutputs(moved []committedOutput) error {
+	var first error
+	for i := len(moved) - 1; i >= 0; i-- {
+		output := moved[i]
+		var err error
+		if output.replaced {
+			err = os.Rename(filepath.Join(g.stageDir, backupDir, output.rel), filepath.Join(g.config.OutputDir, output.rel))
+		} else {
+			err = g.removeOutput(filepath.ToSlash(output.rel))
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// pruneOutputs apaga da pasta de saída os contextos que esta geração não
+// produziu, inclusive os que nenhum manifesto registrou (saídas de versões
+// anteriores ou de outro modo de saída).
+func (g *Generator) pruneOutputs() error {
+	var stale []string
+	err := filepath.WalkDir(g.config.OutputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != g.config.OutputDir && strings.HasPrefix(d.Name(), stagingPrefix) {
+			return fs.SkipDir
+		}
+		if !d.Type().IsRegular() || !contextName(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(g.config.OutputDir, path)
+		if err != nil {
+			return err
+		}
+		if _, produced := g.manifest.current.Outputs[filepath.ToSlash(rel)]; !produced {
+			stale = append(stale, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range stale {
+		if err := g.removeOutput(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contextName informa se o nome segue o padrão *_CONTEXT.* dos contextos
+// de arquivo e de pacote.
+func contextName(name string) bool {
+	matched, _ := filepath.Match("*_CONTEXT.*", name)
+	return matched
+}
+
+// generatedName informa se o nome pode ter sido gravado pelo gerador em
+// qualquer modo e formato.
+func generatedName(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return contextName(name) || base == "00_PROJECT_OVERVIEW" || base == "PROJECT_BUNDLE" ||
+		name == ProjectExportFile || name == FilesExportFile
+}
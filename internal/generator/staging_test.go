@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrepareOutputDirKeepsLiveStaging(t *testing.T) {
+	out := t.TempDir()
+	live := filepath.Join(out, stagingPrefix+"live")
+	stale := filepath.Join(out, stagingPrefix+"stale")
+	for _, dir := range []string{live, stale} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "A_CONTEXT.txt"), []byte("a"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * staleAfter)
+	for _, path := range []string{filepath.Join(stale, "A_CONTEXT.txt"), stale} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := NewGenerator(Config{OutputDir: out})
+	if err := g.prepareOutputDir(); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(g.stageDir)
+
+	if _, err := os.Stat(live); err != nil {
+		t.Errorf("a preparação de uma execução em andamento foi apagada: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("a preparação abandonada não foi apagada: %v", err)
+	}
+}
+
+func TestCommitOutputsRollsBackOnFailure(t *testing.T) {
+	out := t.TempDir()
+	g := NewGenerator(Config{OutputDir: out})
+	if err := g.prepareOutputDir(); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(g.stageDir)
+
+	write := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A é novo, B substitui a versão anterior e C colide com um diretório,
+	// o que faz o terceiro rename falhar
+	write(filepath.Join(out, "B_CONTEXT.txt"), "old")
+	write(filepath.Join(out, "C_CONTEXT.txt", "keep"), "dir")
+	for _, name := range []string{"A_CONTEXT.txt", "B_CONTEXT.txt", "C_CONTEXT.txt"} {
+		write(g.outputPath(name), "new")
+	}
+
+	if err := g.commitOutputs(); err == nil {
+		t.Fatal("esperado erro no rename de C_CONTEXT.txt")
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "A_CONTEXT.txt")); !os.IsNotExist(err) {
+		t.Error("o documento novo A_CONTEXT.txt ficou na saída")
+	}
+	if content, err := os.ReadFile(filepath.Join(out, "B_CONTEXT.txt")); err != nil || string(content) != "old" {
+		t.Errorf("B_CONTEXT.txt = %q, %v; esperada a versão anterior", content, err)
+	}
+	if content, err := os.ReadFile(filepath.Join(out, "C_CONTEXT.txt", "keep")); err != nil || string(content) != "dir" {
+		t.Errorf("o diretório C_CONTEXT.txt foi alterado: %q, %v", content, err)
+	}
+}
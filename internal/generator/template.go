@@ -0,0 +1,222 @@
+package generator
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// templateBlocks são os documentos que um template pode definir com
+// {{define}}; os que faltarem usam o renderer do formato configurado.
+var templateBlocks = []string{"overview", "context", "package", "bundle"}
+
+// Template é um layout text/template para os documentos. Cada bloco recebe o
+// mesmo modelo dos renderers embutidos: "overview" um *OverviewData,
+// "context" um *ContextData, "package" um *PackageContextData e "bundle" um
+// *BundleData. O bloco opcional "extension" define a extensão dos arquivos.
+type Template struct {
+	Name      string
+	tmpl      *template.Template
+	extension string // Vazio usa a extensão do formato
+	digest    string // Hash do texto, para o manifesto
+}
+
+// BuiltinTemplates lista, em ordem alfabética, os templates embutidos.
+func BuiltinTemplates() []string {
+	entries, _ := builtinTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".tmpl"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplate carrega um template embutido pelo nome (ver BuiltinTemplates)
+// ou um arquivo text/template pelo caminho.
+func LoadTemplate(name string) (*Template, error) {
+	source, err := builtinTemplates.ReadFile("templates/" + name + ".tmpl")
+	if err != nil {
+		if source, err = os.ReadFile(name); err != nil {
+			return nil, fmt.Errorf("template %q não é embutido (%s) nem um arquivo legível: %w",
+				name, strings.Join(BuiltinTemplates(), ", "), err)
+		}
+	}
+
+	tmpl, err := template.New(filepath.Base(name)).Funcs(templateFuncs(nil)).Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("erro no template %s: %w", name, err)
+	}
+
+	defined := false
+	for _, block := range templateBlocks {
+		defined = defined || tmpl.Lookup(block) != nil
+	}
+	if !defined {
+		return nil, fmt.Errorf("o template %s não define nenhum dos blocos %s", name, strings.Join(templateBlocks, ", "))
+	}
+
+	t := &Template{Name: name, tmpl: tmpl}
+	if block := tmpl.Lookup("extension"); block != nil {
+		var ext strings.Builder
+		if err := block.Execute(&ext, nil); err != nil {
+			return nil, fmt.Errorf("erro no template %s: %w", name, err)
+		}
+		t.extension = strings.TrimSpace(ext.String())
+		if !strings.HasPrefix(t.extension, ".") || strings.ContainsAny(t.extension, `/\`) {
+			return nil, fmt.Errorf("extensão inválida no template %s: %q", name, t.extension)
+		}
+	}
+
+	h := newInputHash()
+	h.add(string(source))
+	t.digest = h.sum()
+	return t, nil
+}
+
+// templateFuncs são as funções disponíveis nos templates. count conta tokens
+// com o tokenizer da geração; nil serve apenas para o parse.
+func templateFuncs(count func(string) int) template.FuncMap {
+	if count == nil {
+		count = func(string) int { return 0 }
+	}
+	return template.FuncMap{
+		// Caminhos (relativos à origem, com barras)
+		"rel":  relativeTo,
+		"base": path.Base,
+		"dir":  path.Dir,
+
+		// Tokens do texto, com o tokenizer configurado
+		"tokens": count,
+
+		// Código
+		"fence": fence,
+		"cdata": func(code string) string {
+			var content strings.Builder
+			writeCDATA(&content, code)
+			return content.String()
+		},
+		"xml":  xmlText,
+		"note": fileNote,
+
+		// Texto
+		"chain":     formatChain,
+		"join":      strings.Join,
+		"repeat":    strings.Repeat,
+		"upper":     strings.ToUpper,
+		"lower":     strings.ToLower,
+		"add":       func(a, b int) int { return a + b },
+		"timestamp": formatTimestamp,
+	}
+}
+
+// relativeTo retorna o caminho de target relativo à pasta do arquivo from,
+// para links entre documentos.
+func relativeTo(from, target string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(target))
+	if err != nil {
+		return target
+	}
+	return filepath.ToSlash(rel)
+}
+
+// fence envolve o código em um bloco Markdown da linguagem informada (go por
+// padrão), com mais crases do que qualquer sequência presente no código.
+func fence(code string, lang ...string) string {
+	language := "go"
+	if len(lang) > 0 {
+		language = lang[0]
+	}
+	marker := codeFence(code)
+	if !strings.HasSuffix(code, "\n") {
+		code += "\n"
+	}
+	return marker + language + "\n" + code + marker
+}
+
+// formatTimestamp formata a data da geração, ou retorna vazio se ela foi
+// omitida.
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(timestampLayout)
+}
+
+// templateRenderer executa os blocos definidos no template e delega os
+// demais ao renderer do formato. Como a interface renderer não retorna
+// erros, o primeiro erro de execução fica guardado e interrompe a geração
+// antes de qualquer documento chegar à pasta de saída.
+type templateRenderer struct {
+	base     renderer
+	template *Template
+	tmpl     *template.Template
+	mu       sync.Mutex
+	err      error
+}
+
+func newTemplateRenderer(t *Template, base renderer, count func(string) int) *templateRenderer {
+	tmpl := template.Must(t.tmpl.Clone()).Funcs(templateFuncs(count))
+	return &templateRenderer{base: base, template: t, tmpl: tmpl}
+}
+
+func (r *templateRenderer) Extension() string {
+	if r.template.extension != "" {
+		return r.template.extension
+	}
+	return r.base.Extension()
+}
+
+func (r *templateRenderer) Overview(data *OverviewData) string {
+	return renderTemplate(r, "overview", data, r.base.Overview)
+}
+
+func (r *templateRenderer) Context(data *ContextData) string {
+	return renderTemplate(r, "context", data, r.base.Context)
+}
+
+func (r *templateRenderer) Package(data *PackageContextData) string {
+	return renderTemplate(r, "package", data, r.base.Package)
+}
+
+func (r *templateRenderer) Bundle(data *BundleData) string {
+	return renderTemplate(r, "bundle", data, r.base.Bundle)
+}
+
+func (r *templateRenderer) WithPrompt(document, prompt string, bottom bool) string {
+	return r.base.WithPrompt(document, prompt, bottom)
+}
+
+// Err retorna o primeiro erro de execução do template.
+func (r *templateRenderer) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func renderTemplate[T any](r *templateRenderer, block string, data T, fallback func(T) string) string {
+	if r.tmpl.Lookup(block) == nil {
+		return fallback(data)
+	}
+
+	var content strings.Builder
+	if err := r.tmpl.ExecuteTemplate(&content, block, data); err != nil {
+		r.mu.Lock()
+		if r.err == nil {
+			r.err = fmt.Errorf("erro no template %s: %w", r.template.Name, err)
+		}
+		r.mu.Unlock()
+		return ""
+	}
+	return content.String()
+}
@@ -0,0 +1,353 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// textRenderer gera o layout original em texto puro, com emojis e réguas ASCII.
+type textRenderer struct{}
+
+func (textRenderer) Extension() string { return ".txt" }
+
+func (r textRenderer) Overview(data *OverviewData) string {
+	var content strings.Builder
+	r.writeOverview(&content, data)
+	r.writeOverviewFooter(&content)
+	return content.String()
+}
+
+func (r textRenderer) Bundle(data *BundleData) string {
+	var content strings.Builder
+	r.writeOverview(&content, data.Overview)
+
+	content.WriteString("\n📚 SOURCE FILES (dependency order)\n")
+	content.WriteString(strings.Repeat("=", 35) + "\n\n")
+
+	total := len(data.Files)
+	for i, file := range data.Files {
+		content.WriteString(fmt.Sprintf("--- FILE %d/%d: %s ---\n", i+1, total, file.Path))
+		content.WriteString(fmt.Sprintf("Package: %s | LOC: %d | Tokens: %d%s\n\n", file.Package, file.LOC, file.Tokens, fileNote(file, " | ")))
+		content.WriteString(file.Content)
+		content.WriteString("\n\n")
+	}
+
+	// Rodapé
+	content.WriteString(strings.Repeat("─", 50) + "\n")
+	content.WriteString("🤖 Optimized for AI Context Analysis\n")
+	content.WriteString("⚡ Generated by Go Context Generator Pro v2.0\n")
+
+	return content.String()
+}
+
+func (textRenderer) WithPrompt(document, prompt string, bottom bool) string {
+	task := "🎯 TASK\n" + strings.Repeat("=", 50) + "\n" + prompt + "\n"
+	if bottom {
+		return strings.TrimRight(document, "\n") + "\n\n" + task
+	}
+	return task + "\n" + document
+}
+
+func (r textRenderer) writeOverview(content *strings.Builder, data *OverviewData) {
+	// Cabeçalho principal
+	content.WriteString("🚀 GO PROJECT COMPLETE OVERVIEW\n")
+	content.WriteString(strings.Repeat("=", 50) + "\n\n")
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("📅 Generated: %s\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString(fmt.Sprintf("📁 Source Directory: %s\n", data.SourceDir))
+	content.WriteString(fmt.Sprintf("📊 Total Files Analyzed: %d\n\n", data.Stats.TotalFiles))
+
+	// Estatísticas do projeto
+	stats := data.Stats
+	content.WriteString("📈 PROJECT STATISTICS\n")
+	content.WriteString(strings.Repeat("-", 25) + "\n")
+	content.WriteString(fmt.Sprintf("• Total Go Files: %d\n", stats.TotalFiles))
+	content.WriteString(fmt.Sprintf("• Total Packages: %d\n", stats.TotalPackages))
+	content.WriteString(fmt.Sprintf("• Total Imports: %d\n", stats.TotalImports))
+	content.WriteString(fmt.Sprintf("• Total Lines of Code: %d\n", stats.TotalLOC))
+	content.WriteString(fmt.Sprintf("• Largest File: %s (%d bytes)\n", stats.LargestFile, stats.LargestSize))
+	content.WriteString(fmt.Sprintf("• Total Tokens (%s): %d\n\n", stats.Tokenizer, stats.TotalTokens))
+
+	// Estrutura de packages
+	content.WriteString("📦 PACKAGE STRUCTURE\n")
+	content.WriteString(strings.Repeat("-", 25) + "\n")
+	for _, pkg := range data.Packages {
+		content.WriteString(fmt.Sprintf("📦 %s (%d files)\n", pkg.Name, len(pkg.Files)))
+
+		for _, file := range pkg.Files {
+			content.WriteString(fmt.Sprintf("   ├── %s (%d LOC)\n", file.Path, file.LOC))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	// Mapa completo de dependências
+	content.WriteString("🔗 DEPENDENCY MAP\n")
+	content.WriteString(strings.Repeat("-", 20) + "\n")
+	for _, edge := range data.DependencyMap {
+		content.WriteString(fmt.Sprintf("📄 %s\n", edge.File))
+
+		for _, dep := range edge.Dependencies {
+			content.WriteString(fmt.Sprintf("   └─→ %s\n", dep))
+		}
+		content.WriteString("\n")
+	}
+	content.WriteString("\n")
+
+	// Imports externos mais utilizados
+	content.WriteString("📥 TOP EXTERNAL IMPORTS\n")
+	content.WriteString(strings.Repeat("-", 30) + "\n")
+	for _, imp := range data.TopImports {
+		content.WriteString(fmt.Sprintf("• %s (used %d times)\n", imp.Path, imp.Count))
+	}
+
+	// Caminhos excluídos e a regra responsável
+	if len(data.Skipped) > 0 {
+		content.WriteString("\n🚫 SKIPPED PATHS\n")
+		content.WriteString(strings.Repeat("-", 20) + "\n")
+		for _, skipped := range data.Skipped {
+			path := skipped.Path
+			if skipped.IsDir {
+				path += "/"
+			}
+			content.WriteString(fmt.Sprintf("• %s (%s)\n", path, skipped.Rule))
+		}
+	}
+
+	// Arquivos que não puderam ser interpretados
+	if len(data.Problems) > 0 {
+		content.WriteString("\n⚠️ PROBLEMS\n")
+		content.WriteString(strings.Repeat("-", 20) + "\n")
+		for _, problem := range data.Problems {
+			content.WriteString(fmt.Sprintf("• %s: %s%s\n", problem.Position(), problem.Reason, problemNote(problem)))
+			for _, err := range problem.Errors {
+				content.WriteString(fmt.Sprintf("    %s\n", err))
+			}
+		}
+	}
+}
+
+func (textRenderer) writeOverviewFooter(content *strings.Builder) {
+	content.WriteString("\n" + strings.Repeat("─", 50) + "\n")
+	content.WriteString("🤖 Optimized for AI Context Analysis\n")
+	content.WriteString("⚡ Generated by Go Context Generator Pro v2.0\n")
+}
+
+func (r textRenderer) Context(data *ContextData) string {
+	var content strings.Builder
+
+	// Cabeçalho otimizado para IA
+	content.WriteString("🎯 AI CONTEXT FILE\n")
+	content.WriteString(strings.Repeat("=", 30) + "\n\n")
+
+	// Metadados essenciais
+	content.WriteString("📋 FILE METADATA\n")
+	content.WriteString("----------------\n")
+	content.WriteString(fmt.Sprintf("File: %s\n", data.File.Path))
+	content.WriteString(fmt.Sprintf("Package: %s\n", data.File.Package))
+	content.WriteString(fmt.Sprintf("Lines of Code: %d\n", data.File.LOC))
+	content.WriteString(fmt.Sprintf("Tokens (%s): %d\n", data.Tokenizer, data.Tokens))
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("Generated: %s\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString("\n")
+
+	// Imports organizados
+	imports := data.Imports
+	if len(imports.Std)+len(imports.External)+len(imports.Local) > 0 {
+		content.WriteString("📥 DEPENDENCIES\n")
+		content.WriteString("---------------\n")
+
+		if len(imports.Std) > 0 {
+			content.WriteString("Standard Library:\n")
+			for _, imp := range imports.Std {
+				content.WriteString(fmt.Sprintf("  • %s\n", imp))
+			}
+		}
+
+		if len(imports.External) > 0 {
+			content.WriteString("External Packages:\n")
+			for _, imp := range imports.External {
+				content.WriteString(fmt.Sprintf("  • %s\n", imp))
+			}
+		}
+
+		if len(imports.Local) > 0 {
+			content.WriteString("Local Project:\n")
+			for _, imp := range imports.Local {
+				content.WriteString(fmt.Sprintf("  • %s\n", imp))
+			}
+		}
+		content.WriteString("\n")
+	}
+
+	// Estrutura simplificada do projeto
+	content.WriteString("🏗️ PROJECT CONTEXT\n")
+	content.WriteString("------------------\n")
+	r.writeSimplifiedStructure(&content, data.Structure)
+	content.WriteString("\n")
+
+	// Código principal
+	content.WriteString("💻 SOURCE CODE" + fileNote(data.File, " — ") + "\n")
+	content.WriteString(strings.Repeat("=", 15) + "\n\n")
+	content.WriteString(data.File.Content)
+	content.WriteString("\n\n")
+
+	// Dependências locais (código relacionado)
+	if len(data.Dependencies) > 0 {
+		content.WriteString("🔗 RELATED CODE\n")
+		content.WriteString(strings.Repeat("=", 15) + "\n\n")
+
+		for i, dep := range data.Dependencies {
+			content.WriteString(fmt.Sprintf("--- DEPENDENCY %d: %s ---\n", i+1, dep.Path))
+			content.WriteString(fmt.Sprintf("Package: %s | LOC: %d | Tokens: %d%s\n", dep.Package, dep.LOC, dep.Tokens, fileNote(dep.FileData, " | ")))
+			content.WriteString(fmt.Sprintf("Via: %s\n\n", formatChain(dep.Chain)))
+			content.WriteString(dep.Content)
+			content.WriteString("\n\n")
+		}
+	}
+
+	// Dependências descartadas pelo orçamento de tokens
+	if len(data.Omitted) > 0 {
+		content.WriteString("✂️ OMITTED DEPENDENCIES (token budget)\n")
+		content.WriteString(strings.Repeat("-", 38) + "\n")
+		for _, dep := range data.Omitted {
+			content.WriteString(fmt.Sprintf("• %s (%d tokens, via %s)\n", dep.Path, dep.Tokens, formatChain(dep.Chain)))
+		}
+		content.WriteString("\n")
+	}
+
+	r.writeCycles(&content, data.Cycles)
+
+	// Rodapé otimizado
+	content.WriteString(strings.Repeat("─", 40) + "\n")
+	content.WriteString("🤖 AI-OPTIMIZED CONTEXT\n")
+	content.WriteString("⚡ Tokens minimized for efficient processing\n")
+
+	return content.String()
+}
+
+func (r textRenderer) Package(data *PackageContextData) string {
+	var content strings.Builder
+
+	content.WriteString("📦 AI PACKAGE CONTEXT\n")
+	content.WriteString(strings.Repeat("=", 30) + "\n\n")
+
+	content.WriteString("📋 PACKAGE METADATA\n")
+	content.WriteString("-------------------\n")
+	content.WriteString(fmt.Sprintf("Import Path: %s\n", data.ImportPath))
+	content.WriteString(fmt.Sprintf("Package: %s\n", data.Name))
+	content.WriteString(fmt.Sprintf("Directory: %s\n", data.Dir))
+	content.WriteString(fmt.Sprintf("Files: %d\n", len(data.Files)))
+	content.WriteString(fmt.Sprintf("Tokens (%s): %d\n", data.Tokenizer, data.Tokens))
+	if !data.Generated.IsZero() {
+		content.WriteString(fmt.Sprintf("Generated: %s\n", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString("\n")
+
+	imports := data.Imports
+	if len(imports.Std)+len(imports.External)+len(imports.Local) > 0 {
+		content.WriteString("📥 DEPENDENCIES\n")
+		content.WriteString("---------------\n")
+		for _, group := range []struct {
+			title   string
+			imports []string
+		}{{"Standard Library", imports.Std}, {"External Packages", imports.External}, {"Local Project", imports.Local}} {
+			if len(group.imports) == 0 {
+				continue
+			}
+			content.WriteString(group.title + ":\n")
+			for _, imp := range group.imports {
+				content.WriteString(fmt.Sprintf("  • %s\n", imp))
+			}
+		}
+		content.WriteString("\n")
+	}
+
+	content.WriteString("🏗️ PROJECT CONTEXT\n")
+	content.WriteString("------------------\n")
+	r.writeSimplifiedStructure(&content, data.Structure)
+	content.WriteString("\n")
+
+	content.WriteString("💻 PACKAGE SOURCE\n")
+	content.WriteString(strings.Repeat("=", 17) + "\n\n")
+	for _, file := range data.Files {
+		content.WriteString(fmt.Sprintf("--- FILE: %s (LOC: %d | Tokens: %d%s) ---\n", file.Path, file.LOC, file.Tokens, fileNote(file, " | ")))
+		content.WriteString(file.Content)
+		content.WriteString("\n\n")
+	}
+
+	if len(data.Dependencies) > 0 {
+		content.WriteString("🔗 IMPORTED PACKAGES\n")
+		content.WriteString(strings.Repeat("=", 20) + "\n\n")
+		for _, dep := range data.Dependencies {
+			content.WriteString(fmt.Sprintf("=== PACKAGE %s (%s) ===\n", dep.ImportPath, dep.Name))
+			content.WriteString(fmt.Sprintf("Via: %s\n\n", formatChain(dep.Chain)))
+			for _, file := range dep.Files {
+				content.WriteString(fmt.Sprintf("--- FILE: %s (LOC: %d | Tokens: %d%s) ---\n", file.Path, file.LOC, file.Tokens, fileNote(file, " | ")))
+				content.WriteString(file.Content)
+				content.WriteString("\n\n")
+			}
+		}
+	}
+
+	if len(data.Importers) > 0 {
+		content.WriteString("👥 IMPORTED BY (exported API)\n")
+		content.WriteString(strings.Repeat("=", 29) + "\n\n")
+		for _, importer := range data.Importers {
+			content.WriteString(fmt.Sprintf("=== PACKAGE %s (%s) ===\n", importer.ImportPath, importer.Name))
+			if importer.API != "" {
+				content.WriteString(importer.API)
+				content.WriteString("\n")
+			}
+			content.WriteString("\n")
+		}
+	}
+
+	if len(data.Omitted) > 0 {
+		content.WriteString("✂️ OMITTED PACKAGES (token budget)\n")
+		content.WriteString(strings.Repeat("-", 34) + "\n")
+		for _, dep := range data.Omitted {
+			content.WriteString(fmt.Sprintf("• %s (%d tokens, via %s)\n", dep.ImportPath, dep.Tokens, formatChain(dep.Chain)))
+		}
+		content.WriteString("\n")
+	}
+
+	r.writeCycles(&content, data.Cycles)
+
+	content.WriteString(strings.Repeat("─", 40) + "\n")
+	content.WriteString("🤖 AI-OPTIMIZED CONTEXT\n")
+	content.WriteString("⚡ Tokens minimized for efficient processing\n")
+
+	return content.String()
+}
+
+func (textRenderer) writeCycles(content *strings.Builder, cycles [][]string) {
+	if len(cycles) == 0 {
+		return
+	}
+	content.WriteString("⚠️ IMPORT CYCLES\n")
+	content.WriteString(strings.Repeat("-", 16) + "\n")
+	for _, cycle := range cycles {
+		content.WriteString(fmt.Sprintf("• %s\n", formatChain(cycle)))
+	}
+	content.WriteString("\n")
+}
+
+func (textRenderer) writeSimplifiedStructure(content *strings.Builder, structure []PackageData) {
+	for _, pkg := range structure {
+		files := make([]string, 0, len(pkg.Files))
+		for _, file := range pkg.Files {
+			files = append(files, filepath.Base(file.Path))
+		}
+
+		if len(files) <= 3 {
+			content.WriteString(fmt.Sprintf("%s: %s\n", pkg.Name, strings.Join(files, ", ")))
+		} else {
+			content.WriteString(fmt.Sprintf("%s: %s... (+%d more)\n",
+				pkg.Name, strings.Join(files[:3], ", "), len(files)-3))
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayout formata a data da geração nos documentos.
+const timestampLayout = "2006-01-02 15:04:05"
+
+// SourceDateEpoch lê a variável SOURCE_DATE_EPOCH (segundos desde 1970, UTC),
+// a convenção de builds reproduzíveis para fixar as datas gravadas. ok é
+// false quando a variável não está definida.
+func SourceDateEpoch() (t time.Time, ok bool, err error) {
+	value := strings.TrimSpace(os.Getenv("SOURCE_DATE_EPOCH"))
+	if value == "" {
+		return time.Time{}, false, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, false, fmt.Errorf("SOURCE_DATE_EPOCH inválido: %q", value)
+	}
+	return time.Unix(seconds, 0).UTC(), true, nil
+}
+
+// timestamp retorna a data registrada nos documentos de uma geração: zero
+// com OmitTimestamps, que faz os documentos omitirem a linha, Timestamp se
+// definido, ou o horário atual.
+func (g *Generator) timestamp() time.Time {
+	if g.config.OmitTimestamps {
+		return time.Time{}
+	}
+	if !g.config.Timestamp.IsZero() {
+		return g.config.Timestamp
+	}
+	return time.Now()
+}
@@ -0,0 +1,126 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-context-generator/internal/testfixture"
+)
+
+// TestGenerationIsReproducible gera o mesmo projeto duas vezes, com números
+// de workers diferentes, e exige pastas de saída idênticas byte a byte.
+func TestGenerationIsReproducible(t *testing.T) {
+	src := t.TempDir()
+	testfixture.Write(t, src, 8, 4)
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	epoch, ok, err := SourceDateEpoch()
+	if err != nil || !ok {
+		t.Fatalf("SourceDateEpoch() = %v, %v, %v", epoch, ok, err)
+	}
+
+	timestamps := []struct {
+		name   string
+		config Config
+		want   string // Linha de data esperada na visão geral; vazio exige a ausência
+	}{
+		{"no-timestamps", Config{OmitTimestamps: true}, ""},
+		{"source-date-epoch", Config{Timestamp: epoch}, "📅 Generated: 2023-11-14 22:13:20"},
+	}
+
+	for _, mode := range []OutputMode{ModePerFile, ModePackages, ModeBundle} {
+		for _, ts := range timestamps {
+			t.Run(string(mode)+"/"+ts.name, func(t *testing.T) {
+				generate := func(workers int) map[string][]byte {
+					config := ts.config
+					config.SourceDir = src
+					config.OutputDir = t.TempDir()
+					config.Mode = mode
+					config.Workers = workers
+					config.ExportJSON = true
+					config.GraphFormats = []GraphFormat{GraphDOT, GraphMermaid, GraphJSON}
+
+					files := scanFixture(t, src, workers)
+					if err := NewGenerator(config).GenerateContextFiles(context.Background(), files); err != nil {
+						t.Fatal(err)
+					}
+					return readTree(t, config.OutputDir)
+				}
+
+				first, second := generate(1), generate(8)
+				compareTrees(t, first, second)
+
+				var overview []byte
+				for name, content := range first {
+					if strings.HasPrefix(name, "00_PROJECT_OVERVIEW") || strings.HasPrefix(name, "PROJECT_BUNDLE") {
+						overview = content
+					}
+				}
+				if overview == nil {
+					t.Fatal("nenhuma visão geral ou bundle gerado")
+				}
+				if ts.want == "" && bytes.Contains(overview, []byte("📅 Generated:")) {
+					t.Error("data da geração presente com OmitTimestamps")
+				}
+				if ts.want != "" && !bytes.Contains(overview, []byte(ts.want)) {
+					t.Errorf("visão geral sem %q", ts.want)
+				}
+			})
+		}
+	}
+}
+
+// readTree lê todos os arquivos de dir, indexados pelo caminho relativo.
+func readTree(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+	tree := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		tree[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree
+}
+
+func compareTrees(t *testing.T, want, got map[string][]byte) {
+	t.Helper()
+	names := make([]string, 0, len(want)+len(got))
+	for name := range want {
+		names = append(names, name)
+	}
+	for name := range got {
+		if _, ok := want[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		a, inWant := want[name]
+		b, inGot := got[name]
+		switch {
+		case !inWant:
+			t.Errorf("%s só existe na segunda geração", name)
+		case !inGot:
+			t.Errorf("%s só existe na primeira geração", name)
+		case !bytes.Equal(a, b):
+			t.Errorf("%s difere entre as gerações", name)
+		}
+	}
+}
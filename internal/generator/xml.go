@@ -0,0 +1,328 @@
+package generator
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// xmlRenderer gera documentos XML em que cada parte fica entre tags
+// explícitas (<project_overview>, <file>, <dependency>...), que os modelos
+// seguem com mais precisão do que cabeçalhos decorados. Atributos e textos
+// são escapados e o código vai em seções CDATA.
+type xmlRenderer struct{}
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+
+func (xmlRenderer) Extension() string { return ".xml" }
+
+func (r xmlRenderer) Overview(data *OverviewData) string {
+	var content strings.Builder
+	content.WriteString(xmlHeader)
+	r.writeOverview(&content, data, "")
+	return content.String()
+}
+
+func (r xmlRenderer) Bundle(data *BundleData) string {
+	var content strings.Builder
+	content.WriteString(xmlHeader)
+	content.WriteString("<project_bundle>\n")
+	r.writeOverview(&content, data.Overview, "  ")
+
+	total := len(data.Files)
+	content.WriteString(fmt.Sprintf("  <source_files order=\"dependency\" count=\"%d\">\n", total))
+	for _, file := range data.Files {
+		writeXMLFile(&content, "    ", "file", file)
+	}
+	content.WriteString("  </source_files>\n")
+	content.WriteString("</project_bundle>\n")
+	return content.String()
+}
+
+// WithPrompt coloca o prompt em um elemento <task> logo após a tag de
+// abertura do elemento raiz, ou antes da tag de fechamento, para que o
+// documento continue bem formado.
+func (xmlRenderer) WithPrompt(document, prompt string, bottom bool) string {
+	task := "  <task>" + xmlText(prompt) + "</task>\n"
+	body := strings.TrimPrefix(document, xmlHeader)
+	if bottom {
+		trimmed := strings.TrimRight(body, "\n")
+		end := strings.LastIndex(trimmed, "\n") + 1
+		return xmlHeader + trimmed[:end] + task + trimmed[end:] + "\n"
+	}
+	start := strings.Index(body, "\n") + 1
+	return xmlHeader + body[:start] + task + body[start:]
+}
+
+func (xmlRenderer) writeOverview(content *strings.Builder, data *OverviewData, indent string) {
+	content.WriteString(indent + "<project_overview" + xmlAttr("source_dir", data.SourceDir))
+	if !data.Generated.IsZero() {
+		content.WriteString(xmlAttr("generated", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString(">\n")
+
+	stats := data.Stats
+	content.WriteString(indent + "  <statistics" +
+		xmlAttr("files", strconv.Itoa(stats.TotalFiles)) +
+		xmlAttr("packages", strconv.Itoa(stats.TotalPackages)) +
+		xmlAttr("imports", strconv.Itoa(stats.TotalImports)) +
+		xmlAttr("loc", strconv.Itoa(stats.TotalLOC)) +
+		xmlAttr("tokens", strconv.Itoa(stats.TotalTokens)) +
+		xmlAttr("tokenizer", stats.Tokenizer) +
+		xmlAttr("largest_file", stats.LargestFile) +
+		xmlAttr("largest_size", strconv.FormatInt(stats.LargestSize, 10)) + "/>\n")
+
+	content.WriteString(indent + "  <packages>\n")
+	for _, pkg := range data.Packages {
+		content.WriteString(indent + "    <package" + xmlAttr("name", pkg.Name) + xmlAttr("files", strconv.Itoa(len(pkg.Files))) + ">\n")
+		for _, file := range pkg.Files {
+			content.WriteString(indent + "      <file" + xmlAttr("path", file.Path) +
+				xmlAttr("loc", strconv.Itoa(file.LOC)) + xmlAttr("tokens", strconv.Itoa(file.Tokens)) + "/>\n")
+		}
+		content.WriteString(indent + "    </package>\n")
+	}
+	content.WriteString(indent + "  </packages>\n")
+
+	if len(data.DependencyMap) > 0 {
+		content.WriteString(indent + "  <dependency_map>\n")
+		for _, edge := range data.DependencyMap {
+			content.WriteString(indent + "    <file" + xmlAttr("path", edge.File) + ">\n")
+			for _, dep := range edge.Dependencies {
+				content.WriteString(indent + "      <depends_on" + xmlAttr("path", dep) + "/>\n")
+			}
+			content.WriteString(indent + "    </file>\n")
+		}
+		content.WriteString(indent + "  </dependency_map>\n")
+	}
+
+	if len(data.TopImports) > 0 {
+		content.WriteString(indent + "  <top_imports>\n")
+		for _, imp := range data.TopImports {
+			content.WriteString(indent + "    <import" + xmlAttr("path", imp.Path) + xmlAttr("used_by", strconv.Itoa(imp.Count)) + "/>\n")
+		}
+		content.WriteString(indent + "  </top_imports>\n")
+	}
+
+	if len(data.Skipped) > 0 {
+		content.WriteString(indent + "  <skipped_paths>\n")
+		for _, skipped := range data.Skipped {
+			path := skipped.Path
+			if skipped.IsDir {
+				path += "/"
+			}
+			content.WriteString(indent + "    <skipped" + xmlAttr("path", path) + xmlAttr("rule", skipped.Rule) + "/>\n")
+		}
+		content.WriteString(indent + "  </skipped_paths>\n")
+	}
+
+	if len(data.Problems) > 0 {
+		content.WriteString(indent + "  <problems>\n")
+		for _, problem := range data.Problems {
+			content.WriteString(indent + "    <problem" + xmlAttr("position", problem.Position()) +
+				xmlAttr("reason", problem.Reason) + xmlAttr("included", strconv.FormatBool(problem.Included)) + ">\n")
+			for _, err := range problem.Errors {
+				content.WriteString(indent + "      <error>" + xmlText(err) + "</error>\n")
+			}
+			content.WriteString(indent + "    </problem>\n")
+		}
+		content.WriteString(indent + "  </problems>\n")
+	}
+
+	content.WriteString(indent + "</project_overview>\n")
+}
+
+func (r xmlRenderer) Context(data *ContextData) string {
+	var content strings.Builder
+	content.WriteString(xmlHeader)
+
+	content.WriteString("<file_context" + xmlAttr("path", data.File.Path) + xmlAttr("package", data.File.Package) +
+		xmlAttr("tokens", strconv.Itoa(data.Tokens)) + xmlAttr("tokenizer", data.Tokenizer))
+	if !data.Generated.IsZero() {
+		content.WriteString(xmlAttr("generated", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString(">\n")
+
+	writeXMLImports(&content, data.Imports)
+
+	content.WriteString("  <project_structure>\n")
+	for _, pkg := range data.Structure {
+		names := make([]string, 0, len(pkg.Files))
+		for _, file := range pkg.Files {
+			names = append(names, filepath.Base(file.Path))
+		}
+		if len(names) > 3 {
+			names = append(names[:3], fmt.Sprintf("… (+%d more)", len(pkg.Files)-3))
+		}
+		content.WriteString("    <package" + xmlAttr("name", pkg.Name) + xmlAttr("files", strconv.Itoa(len(pkg.Files))) + ">" +
+			xmlText(strings.Join(names, ", ")) + "</package>\n")
+	}
+	content.WriteString("  </project_structure>\n")
+
+	writeXMLFile(&content, "  ", "file", data.File)
+
+	if len(data.Dependencies) > 0 {
+		content.WriteString("  <dependencies>\n")
+		for _, dep := range data.Dependencies {
+			writeXMLFile(&content, "    ", "dependency", dep.FileData,
+				xmlAttr("depth", strconv.Itoa(dep.Depth)), xmlAttr("via", formatChain(dep.Chain)))
+		}
+		content.WriteString("  </dependencies>\n")
+	}
+
+	if len(data.Omitted) > 0 {
+		content.WriteString("  <omitted_dependencies reason=\"token budget\">\n")
+		for _, dep := range data.Omitted {
+			content.WriteString("    <dependency" + xmlAttr("path", dep.Path) + xmlAttr("tokens", strconv.Itoa(dep.Tokens)) +
+				xmlAttr("via", formatChain(dep.Chain)) + "/>\n")
+		}
+		content.WriteString("  </omitted_dependencies>\n")
+	}
+
+	writeXMLCycles(&content, data.Cycles)
+
+	content.WriteString("</file_context>\n")
+	return content.String()
+}
+
+func (r xmlRenderer) Package(data *PackageContextData) string {
+	var content strings.Builder
+	content.WriteString(xmlHeader)
+
+	content.WriteString("<package_context" + xmlAttr("import_path", data.ImportPath) + xmlAttr("name", data.Name) +
+		xmlAttr("dir", data.Dir) + xmlAttr("files", strconv.Itoa(len(data.Files))) +
+		xmlAttr("tokens", strconv.Itoa(data.Tokens)) + xmlAttr("tokenizer", data.Tokenizer))
+	if !data.Generated.IsZero() {
+		content.WriteString(xmlAttr("generated", data.Generated.Format(timestampLayout)))
+	}
+	content.WriteString(">\n")
+
+	writeXMLImports(&content, data.Imports)
+
+	for _, file := range data.Files {
+		writeXMLFile(&content, "  ", "file", file)
+	}
+
+	if len(data.Dependencies) > 0 {
+		content.WriteString("  <imported_packages>\n")
+		for _, dep := range data.Dependencies {
+			content.WriteString("    <package" + xmlAttr("import_path", dep.ImportPath) + xmlAttr("via", formatChain(dep.Chain)) + ">\n")
+			for _, file := range dep.Files {
+				writeXMLFile(&content, "      ", "file", file)
+			}
+			content.WriteString("    </package>\n")
+		}
+		content.WriteString("  </imported_packages>\n")
+	}
+
+	if len(data.Importers) > 0 {
+		content.WriteString("  <imported_by view=\"exported API\">\n")
+		for _, importer := range data.Importers {
+			if importer.API == "" {
+				content.WriteString("    <package" + xmlAttr("import_path", importer.ImportPath) + "/>\n")
+				continue
+			}
+			content.WriteString("    <package" + xmlAttr("import_path", importer.ImportPath) + ">")
+			writeCDATA(&content, importer.API)
+			content.WriteString("</package>\n")
+		}
+		content.WriteString("  </imported_by>\n")
+	}
+
+	if len(data.Omitted) > 0 {
+		content.WriteString("  <omitted_packages reason=\"token budget\">\n")
+		for _, dep := range data.Omitted {
+			content.WriteString("    <package" + xmlAttr("import_path", dep.ImportPath) + xmlAttr("tokens", strconv.Itoa(dep.Tokens)) +
+				xmlAttr("via", formatChain(dep.Chain)) + "/>\n")
+		}
+		content.WriteString("  </omitted_packages>\n")
+	}
+
+	writeXMLCycles(&content, data.Cycles)
+
+	content.WriteString("</package_context>\n")
+	return content.String()
+}
+
+// writeXMLFile escreve um arquivo com os metadados como atributos e o código
+// em CDATA. extra são atributos adicionais já formatados por xmlAttr.
+func writeXMLFile(content *strings.Builder, indent, tag string, file FileData, extra ...string) {
+	content.WriteString(indent + "<" + tag + xmlAttr("path", file.Path) + xmlAttr("package", file.Package) +
+		xmlAttr("loc", strconv.Itoa(file.LOC)) + xmlAttr("tokens", strconv.Itoa(file.Tokens)))
+	for _, attr := range extra {
+		content.WriteString(attr)
+	}
+	switch {
+	case file.Unparseable:
+		content.WriteString(` view="unparseable, raw source"`)
+	case file.Skeleton:
+		content.WriteString(` view="skeleton"`)
+	}
+	content.WriteString(">")
+	writeCDATA(content, file.Content)
+	content.WriteString("</" + tag + ">\n")
+}
+
+func writeXMLImports(content *strings.Builder, imports ImportGroups) {
+	if len(imports.Std)+len(imports.External)+len(imports.Local) == 0 {
+		return
+	}
+	content.WriteString("  <imports>\n")
+	for _, group := range []struct {
+		kind    string
+		imports []string
+	}{{"std", imports.Std}, {"external", imports.External}, {"local", imports.Local}} {
+		for _, imp := range group.imports {
+			content.WriteString("    <import" + xmlAttr("kind", group.kind) + xmlAttr("path", imp) + "/>\n")
+		}
+	}
+	content.WriteString("  </imports>\n")
+}
+
+func writeXMLCycles(content *strings.Builder, cycles [][]string) {
+	if len(cycles) == 0 {
+		return
+	}
+	content.WriteString("  <import_cycles>\n")
+	for _, cycle := range cycles {
+		content.WriteString("    <cycle>" + xmlText(formatChain(cycle)) + "</cycle>\n")
+	}
+	content.WriteString("  </import_cycles>\n")
+}
+
+// writeCDATA escreve o código em uma seção CDATA, começando e terminando em
+// linhas próprias. Cada "]]>" do código fecha a seção e abre outra entre
+// "]]" e ">", e caracteres proibidos no XML viram U+FFFD.
+func writeCDATA(content *strings.Builder, code string) {
+	code = strings.Map(xmlChar, code)
+	content.WriteString("<![CDATA[\n")
+	content.WriteString(strings.ReplaceAll(code, "]]>", "]]]]><![CDATA[>"))
+	if !strings.HasSuffix(code, "\n") {
+		content.WriteString("\n")
+	}
+	content.WriteString("]]>")
+}
+
+// xmlChar troca os caracteres que o XML 1.0 não aceita, nem escapados.
+func xmlChar(r rune) rune {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return r
+	case r < 0x20, r >= 0xD800 && r <= 0xDFFF, r == 0xFFFE, r == 0xFFFF:
+		return '\uFFFD'
+	}
+	return r
+}
+
+// xmlAttr formata um atributo precedido de espaço, com o valor escapado.
+func xmlAttr(name, value string) string {
+	return " " + name + `="` + xmlText(value) + `"`
+}
+
+// xmlText escapa &, <, >, aspas e quebras de linha.
+func xmlText(value string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
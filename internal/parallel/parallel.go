@@ -0,0 +1,98 @@
+// Package parallel executa tarefas indexadas em um pool limitado de goroutines.
+package parallel
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Workers normaliza o tamanho do pool: valores <= 0 usam GOMAXPROCS.
+func Workers(n int) int {
+	if n <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return n
+}
+
+// ForEach executa fn(i) para cada i em [0, n) com no máximo workers
+// goroutines (ver Workers). Os índices são distribuídos em ordem crescente;
+// depois de uma falha ou do cancelamento de ctx, os ainda não iniciados são
+// pulados. Retorna o erro do menor índice que falhou, para que o resultado
+// não dependa do escalonamento, ou ctx.Err() se ctx foi cancelado.
+func ForEach(ctx context.Context, n, workers int, fn func(i int) error) error {
+	workers = Workers(workers)
+	if workers > n {
+		workers = n
+	}
+
+	errs := make([]error, n)
+	var next atomic.Int64
+	var failed atomic.Bool
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !failed.Load() && ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				if err := fn(i); err != nil {
+					errs[i] = err
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Progress serializa chamadas de progresso vindas de vários workers: cada
+// Done incrementa o contador e chama o callback com o valor atualizado,
+// sempre crescente e nunca em paralelo.
+type Progress struct {
+	mu       sync.Mutex
+	done     int
+	total    int
+	callback func(current, total int)
+}
+
+// NewProgress cria um contador para total tarefas; callback pode ser nil.
+func NewProgress(total int, callback func(current, total int)) *Progress {
+	return &Progress{total: total, callback: callback}
+}
+
+// Start informa o início (0 de total).
+func (p *Progress) Start() {
+	if p.callback == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.callback(p.done, p.total)
+}
+
+// Done registra uma tarefa concluída.
+func (p *Progress) Done() {
+	if p.callback == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	p.callback(p.done, p.total)
+}
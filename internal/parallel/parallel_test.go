@@ -0,0 +1,44 @@
+package parallel
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProgressNeverGoesBackwards(t *testing.T) {
+	const total = 500
+
+	type call struct{ current, total int }
+	var calls []call // Sem lock: Progress garante chamadas serializadas
+	progress := NewProgress(total, func(current, total int) {
+		calls = append(calls, call{current, total})
+	})
+	progress.Start()
+
+	err := ForEach(context.Background(), total, 8, func(i int) error {
+		if i%7 == 0 {
+			time.Sleep(time.Microsecond) // Embaralhar a ordem de conclusão
+		}
+		progress.Done()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(calls) != total+1 {
+		t.Fatalf("%d chamadas, want %d", len(calls), total+1)
+	}
+	if calls[0] != (call{0, total}) {
+		t.Errorf("primeira chamada = %v, want (0, %d)", calls[0], total)
+	}
+	for i := 1; i < len(calls); i++ {
+		if calls[i].current != calls[i-1].current+1 || calls[i].total != total {
+			t.Fatalf("chamada %d = %v depois de %v", i, calls[i], calls[i-1])
+		}
+	}
+	if last := calls[len(calls)-1]; last != (call{total, total}) {
+		t.Errorf("última chamada = %v, want (%d, %d)", last, total, total)
+	}
+}
@@ -0,0 +1,131 @@
+// Package prompt carrega as instruções de tarefa ("revise a concorrência",
+// "escreva testes"...) que o gerador insere em cada contexto. Os presets vêm
+// da pasta de prompts da configuração, onde podem ser editados ou
+// acrescentados como arquivos .txt; os embutidos no binário valem para os
+// nomes que não estão na pasta.
+package prompt
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed presets/*.txt
+var presets embed.FS
+
+// Extension é a extensão dos presets na pasta de prompts.
+const Extension = ".txt"
+
+// Data preenche os placeholders de um prompt, como {{.File}} e
+// {{.Dependencies}}.
+type Data struct {
+	Target       string // O que o contexto apresenta: o arquivo, o pacote ou o projeto
+	File         string // Arquivo principal, relativo à origem; vazio em contextos de pacote e no bundle
+	Package      string // Nome do pacote; vazio no bundle
+	ImportPath   string // Import path do pacote; vazio no bundle
+	Dependencies List   // Arquivos (ou pacotes, no modo pacotes) locais incluídos no contexto
+}
+
+// List imprime os itens separados por vírgula, para que {{.Dependencies}}
+// funcione direto no texto; {{range}} e {{if}} continuam disponíveis.
+type List []string
+
+func (l List) String() string {
+	return strings.Join(l, ", ")
+}
+
+// Prompt é um preset já interpretado.
+type Prompt struct {
+	Name   string
+	Source string // Texto original, com os placeholders
+	tmpl   *template.Template
+}
+
+// Install copia os presets embutidos para a pasta dir quando ela ainda não
+// existe, para que o usuário encontre os textos prontos para editar. Uma
+// pasta existente não é alterada: presets editados continuam como estão e um
+// preset apagado volta a usar o texto embutido.
+func Install(dir string) error {
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	entries, err := presets.ReadDir("presets")
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		source, err := presets.ReadFile("presets/" + entry.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), source, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names lista, em ordem alfabética, os presets embutidos e os da pasta dir.
+func Names(dir string) []string {
+	seen := make(map[string]bool)
+	entries, _ := presets.ReadDir("presets")
+	if user, err := os.ReadDir(dir); err == nil {
+		entries = append(entries, user...)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == Extension {
+			seen[strings.TrimSuffix(entry.Name(), Extension)] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load carrega um preset pelo nome — primeiro da pasta dir, depois dos
+// embutidos — ou um arquivo pelo caminho.
+func Load(name, dir string) (*Prompt, error) {
+	source, err := os.ReadFile(filepath.Join(dir, name+Extension))
+	if err != nil {
+		source, err = presets.ReadFile("presets/" + name + Extension)
+	}
+	if err != nil {
+		if source, err = os.ReadFile(name); err != nil {
+			return nil, fmt.Errorf("prompt %q não encontrado em %s, nos embutidos (%s) nem como arquivo: %w",
+				name, dir, strings.Join(Names(dir), ", "), err)
+		}
+	}
+
+	tmpl, err := template.New(name).Parse(string(source))
+	if err != nil {
+		return nil, fmt.Errorf("erro no prompt %s: %w", name, err)
+	}
+	p := &Prompt{Name: name, Source: string(source), tmpl: tmpl}
+
+	// Placeholders inexistentes aparecem já no carregamento
+	if _, err := p.Render(Data{}); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Render preenche os placeholders, sem espaços nas pontas.
+func (p *Prompt) Render(data Data) (string, error) {
+	var text strings.Builder
+	if err := p.tmpl.Execute(&text, data); err != nil {
+		return "", fmt.Errorf("erro no prompt %s: %w", p.Name, err)
+	}
+	return strings.TrimSpace(text.String()), nil
+}
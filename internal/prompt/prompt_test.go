@@ -0,0 +1,117 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInstall(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "prompts")
+	if err := Install(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	embedded, _ := presets.ReadDir("presets")
+	for _, entry := range embedded {
+		want, _ := presets.ReadFile("presets/" + entry.Name())
+		got, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatalf("preset %s não copiado: %v", entry.Name(), err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("cópia de %s difere do embutido", entry.Name())
+		}
+	}
+
+	// Uma pasta existente não é alterada
+	edited := filepath.Join(dir, "explain.txt")
+	if err := os.WriteFile(edited, []byte("Resuma {{.Target}}."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "code-review.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := Install(dir); err != nil {
+		t.Fatal(err)
+	}
+	if source, _ := os.ReadFile(edited); string(source) != "Resuma {{.Target}}." {
+		t.Errorf("preset editado sobrescrito: %q", source)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "code-review.txt")); !os.IsNotExist(err) {
+		t.Error("preset apagado foi recriado")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("explain.txt", "Resuma {{.Target}}.")
+	write("security.txt", "Procure falhas de segurança em {{.Target}}{{if .Dependencies}} e em {{.Dependencies}}{{end}}.")
+	write("broken.txt", "{{.Missing}}")
+	external := filepath.Join(t.TempDir(), "meu-prompt.md")
+	if err := os.WriteFile(external, []byte("Documente {{.File}}."), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := Data{Target: "o pacote example.com/x", Dependencies: List{"a.go", "b.go"}}
+	tests := []struct {
+		name string
+		dir  string
+		want string // Prefixo do texto renderizado
+	}{
+		{"explain", dir, "Resuma o pacote example.com/x."}, // Pasta substitui o embutido
+		{"security", dir, "Procure falhas de segurança em o pacote example.com/x e em a.go, b.go."},
+		{"code-review", dir, "Revise o pacote example.com/x como um engenheiro Go sênior."}, // Embutido como fallback
+		{"explain", filepath.Join(dir, "inexistente"), "Explique o pacote example.com/x"},
+		{external, dir, "Documente ."},
+	}
+	for _, tt := range tests {
+		p, err := Load(tt.name, tt.dir)
+		if err != nil {
+			t.Errorf("Load(%q): %v", tt.name, err)
+			continue
+		}
+		got, err := p.Render(data)
+		if err != nil {
+			t.Errorf("Render(%q): %v", tt.name, err)
+			continue
+		}
+		if !strings.HasPrefix(got, tt.want) {
+			t.Errorf("Load(%q) = %q, esperado o início %q", tt.name, got, tt.want)
+		}
+	}
+
+	for _, name := range []string{"broken", "inexistente"} {
+		if _, err := Load(name, dir); err == nil {
+			t.Errorf("Load(%q) deveria falhar", name)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"explain.txt", "security.txt", "notas.md"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "pasta.txt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"code-review", "concurrency-review", "explain", "security", "table-tests"}
+	if got := Names(dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names = %v, esperado %v", got, want)
+	}
+	want = []string{"code-review", "concurrency-review", "explain", "table-tests"}
+	if got := Names(filepath.Join(dir, "inexistente")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Names sem pasta = %v, esperado %v", got, want)
+	}
+}
@@ -0,0 +1,87 @@
+// Package testfixture gera projetos Go sintéticos para os testes e
+// benchmarks do scanner e do gerador.
+package testfixture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Module é o caminho do módulo dos projetos gerados.
+const Module = "example.com/fixture"
+
+// Write cria em dir um módulo com packages pacotes de filesPerPackage
+// arquivos cada. O pacote pN importa pN-1 e, a cada três pacotes, também
+// pN-2, para que haja dependências diretas e indiretas; o main importa o
+// último pacote. Cada arquivo tem tipos, métodos, comentários e imports da
+// biblioteca padrão, para exercitar a limpeza e o recorte.
+func Write(tb testing.TB, dir string, packages, filesPerPackage int) {
+	tb.Helper()
+
+	write := func(rel, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			tb.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+
+	write("go.mod", "module "+Module+"\n\ngo 1.21\n")
+
+	for p := 0; p < packages; p++ {
+		var deps []int
+		if p > 0 {
+			deps = append(deps, p-1)
+		}
+		if p > 1 && p%3 == 0 {
+			deps = append(deps, p-2)
+		}
+		for f := 0; f < filesPerPackage; f++ {
+			write(fmt.Sprintf("pkg/p%d/f%d.go", p, f), sourceFile(p, f, deps))
+		}
+	}
+
+	main := "package main\n\nimport \"fmt\"\n"
+	if packages > 0 {
+		main = fmt.Sprintf("package main\n\nimport (\n\t\"fmt\"\n\n\tlast %q\n)\n", fmt.Sprintf("%s/pkg/p%d", Module, packages-1))
+	}
+	main += "\nfunc main() {\n"
+	if packages > 0 {
+		main += "\tfmt.Println(last.Compute0(1))\n"
+	} else {
+		main += "\tfmt.Println()\n"
+	}
+	write("main.go", main+"}\n")
+}
+
+func sourceFile(p, f int, deps []int) string {
+	var src strings.Builder
+	fmt.Fprintf(&src, "// Package p%d é gerado para testes.\npackage p%d\n\n", p, p)
+
+	src.WriteString("import (\n\t\"fmt\"\n\t\"strings\"\n\t\"sync\"\n")
+	if len(deps) > 0 {
+		src.WriteString("\n")
+		for _, dep := range deps {
+			fmt.Fprintf(&src, "\tp%d %q\n", dep, fmt.Sprintf("%s/pkg/p%d", Module, dep))
+		}
+	}
+	src.WriteString(")\n\n")
+
+	fmt.Fprintf(&src, "// Item%d guarda um valor protegido por mutex.\n", f)
+	fmt.Fprintf(&src, "type Item%d struct {\n\tmu    sync.Mutex\n\tName  string\n\tCount int // contador\n}\n\n", f)
+	fmt.Fprintf(&src, "// Add incrementa o contador.\nfunc (i *Item%d) Add(n int) int {\n\ti.mu.Lock()\n\tdefer i.mu.Unlock()\n\n\ti.Count += n\n\treturn i.Count\n}\n\n", f)
+	fmt.Fprintf(&src, "// Compute%d combina o valor com as dependências.\nfunc Compute%d(n int) string {\n", f, f)
+	src.WriteString("\tparts := []string{fmt.Sprint(n)}\n")
+	for _, dep := range deps {
+		fmt.Fprintf(&src, "\tparts = append(parts, p%d.Compute0(n+1))\n", dep)
+	}
+	src.WriteString("\t// Junta as partes\n\treturn strings.Join(parts, \",\")\n}\n\n")
+	fmt.Fprintf(&src, "func helper%d(values []int) (total int) {\n\tfor _, v := range values {\n\t\ttotal += v\n\t}\n\treturn total\n}\n", f)
+
+	return src.String()
+}
@@ -0,0 +1,174 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Expressões de pré-tokenização de cada vocabulário, que dividem o texto
+// antes das mesclagens. O RE2 do Go não suporta o lookahead "\s+(?!\S)",
+// tratado manualmente em splitPieces.
+var (
+	cl100kPattern = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+	// O o200k_base separa palavras em CamelCase, anexa as contrações à
+	// palavra e mantém "/" junto da pontuação.
+	o200kPattern = regexp.MustCompile(`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+`)
+)
+
+// pretokenizers associa o nome do vocabulário à sua expressão. Vocabulários
+// com outros nomes usam a do cl100k_base.
+var pretokenizers = map[string]*regexp.Regexp{
+	"cl100k_base": cl100kPattern,
+	"o200k_base":  o200kPattern,
+}
+
+// BPE é um tokenizer byte-pair encoding compatível com os arquivos .tiktoken
+// (cl100k_base, o200k_base): uma linha por token, com os bytes em base64 e o
+// rank de mesclagem.
+type BPE struct {
+	name    string
+	ranks   map[string]int
+	pattern *regexp.Regexp
+}
+
+// LoadBPE carrega um vocabulário no formato .tiktoken. A pré-tokenização é
+// escolhida pelo nome do arquivo (ex.: o200k_base.tiktoken); nomes
+// desconhecidos usam a do cl100k_base.
+func LoadBPE(path string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao abrir vocabulário: %w", err)
+	}
+	defer f.Close()
+
+	ranks := make(map[string]int)
+	sc := bufio.NewScanner(f)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: linha inválida", path, lineNum)
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: token inválido: %w", path, lineNum, err)
+		}
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: rank inválido: %w", path, lineNum, err)
+		}
+		ranks[string(token)] = rank
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("vocabulário vazio: %s", path)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ".tiktoken")
+	pattern, ok := pretokenizers[name]
+	if !ok {
+		pattern = cl100kPattern
+	}
+
+	return &BPE{
+		name:    name,
+		ranks:   ranks,
+		pattern: pattern,
+	}, nil
+}
+
+func (b *BPE) Name() string { return b.name }
+
+func (b *BPE) Count(text string) int {
+	count := 0
+	for _, piece := range splitPieces(b.pattern, text) {
+		if _, ok := b.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(b.mergePiece(piece))
+	}
+	return count
+}
+
+// mergePiece aplica as mesclagens BPE sobre os bytes de um trecho, sempre
+// unindo o par adjacente de menor rank, e retorna as partes resultantes.
+func (b *BPE) mergePiece(piece string) []string {
+	parts := make([]string, 0, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts = append(parts, piece[i:i+1])
+	}
+
+	for len(parts) > 1 {
+		best, bestRank := -1, 0
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := b.ranks[parts[i]+parts[i+1]]; ok && (best < 0 || rank < bestRank) {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	return parts
+}
+
+// splitPieces divide o texto, segundo a expressão do vocabulário, nos
+// trechos que o BPE codifica isoladamente.
+func splitPieces(pattern *regexp.Regexp, text string) []string {
+	var pieces []string
+
+	for pos := 0; pos < len(text); {
+		loc := pattern.FindStringIndex(text[pos:])
+		if loc == nil || loc[0] != 0 || loc[1] == 0 {
+			// Não deveria ocorrer: a expressão cobre qualquer caractere
+			_, size := utf8.DecodeRuneInString(text[pos:])
+			pieces = append(pieces, text[pos:pos+size])
+			pos += size
+			continue
+		}
+
+		end := pos + loc[1]
+		piece := text[pos:end]
+
+		// "\s+(?!\S)": um bloco de espaços seguido de texto deixa o último
+		// espaço para o próximo trecho
+		if end < len(text) && isSpace(piece) && !strings.ContainsAny(piece, "\r\n") {
+			next, _ := utf8.DecodeRuneInString(text[end:])
+			_, lastSize := utf8.DecodeLastRuneInString(piece)
+			if !unicode.IsSpace(next) && len(piece) > lastSize {
+				end -= lastSize
+				piece = text[pos:end]
+			}
+		}
+
+		pieces = append(pieces, piece)
+		pos = end
+	}
+
+	return pieces
+}
+
+func isSpace(s string) bool {
+	return strings.TrimSpace(s) == ""
+}
@@ -0,0 +1,154 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeVocab grava um vocabulário .tiktoken com os tokens na ordem dos ranks.
+func writeVocab(t *testing.T, dir, name string, tokens ...string) string {
+	t.Helper()
+	var content strings.Builder
+	for rank, token := range tokens {
+		fmt.Fprintf(&content, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	path := filepath.Join(dir, name+".tiktoken")
+	if err := os.WriteFile(path, []byte(content.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fixtureVocab tem os bytes isolados e algumas mesclagens; " c" tem rank
+// maior que "ab", para que a ordem das mesclagens faça diferença.
+var fixtureVocab = []string{" ", "a", "b", "c", "ab", "abc", " c"}
+
+func TestSplitPieces(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		text    string
+		want    []string
+	}{
+		{"palavras", "cl100k_base", "Hello world", []string{"Hello", " world"}},
+		{"espaços antes de texto", "cl100k_base", "x  = 1", []string{"x", " ", " =", " ", "1"}},
+		{"espaços no fim", "cl100k_base", "x   ", []string{"x", "   "}},
+		{"números de até 3 dígitos", "cl100k_base", "12345", []string{"123", "45"}},
+		{"quebras de linha", "cl100k_base", "a\n\n\tb", []string{"a", "\n\n", "\tb"}},
+		{"contração separada", "cl100k_base", "don't", []string{"don", "'t"}},
+		{"camel case junto", "cl100k_base", "HelloWorld", []string{"HelloWorld"}},
+		{"camel case separado", "o200k_base", "HelloWorld", []string{"Hello", "World"}},
+		{"sigla no fim", "o200k_base", "getHTTP", []string{"get", "HTTP"}},
+		{"contração junto", "o200k_base", "don't", []string{"don't"}},
+		{"o200k números", "o200k_base", "x := 12345", []string{"x", " :=", " ", "123", "45"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitPieces(pretokenizers[tt.pattern], tt.text)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitPieces(%q) = %q, esperado %q", tt.text, got, tt.want)
+			}
+			if joined := strings.Join(got, ""); joined != tt.text {
+				t.Errorf("os trechos não reconstroem o texto: %q", joined)
+			}
+		})
+	}
+}
+
+func TestMergePiece(t *testing.T) {
+	b, err := LoadBPE(writeVocab(t, t.TempDir(), "fixture", fixtureVocab...))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		piece string
+		want  []string
+	}{
+		{"a", []string{"a"}},
+		{"abc", []string{"abc"}},
+		{"cab", []string{"c", "ab"}},
+		{" cab", []string{" c", "ab"}}, // "ab" (rank 4) antes de " c" (rank 6)
+		{"bca", []string{"b", "c", "a"}},
+		{"xab", []string{"x", "ab"}}, // Bytes fora do vocabulário ficam sozinhos
+	}
+	for _, tt := range tests {
+		if got := b.mergePiece(tt.piece); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("mergePiece(%q) = %q, esperado %q", tt.piece, got, tt.want)
+		}
+	}
+
+	for text, want := range map[string]int{
+		"":        0,
+		"abc":     1,
+		"abc cab": 3, // "abc" + " c" "ab"
+		"cab  c":  4, // "c" "ab" + " " + " c"
+	} {
+		if got := b.Count(text); got != want {
+			t.Errorf("Count(%q) = %d, esperado %d", text, got, want)
+		}
+	}
+}
+
+func TestLoadBPE(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("pré-tokenização pelo nome", func(t *testing.T) {
+		for name, want := range map[string][]string{
+			"cl100k_base": {"HelloWorld"},
+			"o200k_base":  {"Hello", "World"},
+			"custom":      {"HelloWorld"},
+		} {
+			b, err := LoadBPE(writeVocab(t, dir, name, fixtureVocab...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.Name() != name {
+				t.Errorf("Name() = %q, esperado %q", b.Name(), name)
+			}
+			if got := splitPieces(b.pattern, "HelloWorld"); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: trechos %q, esperado %q", name, got, want)
+			}
+		}
+	})
+
+	t.Run("via New", func(t *testing.T) {
+		b, err := New("o200k_base", dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Name() != "o200k_base" {
+			t.Errorf("Name() = %q", b.Name())
+		}
+	})
+
+	invalid := map[string]string{
+		"linha inválida": "YQ==\n",
+		"token inválido": "@@@ 0\n",
+		"rank inválido":  "YQ== x\n",
+		"vazio":          "\n\n",
+	}
+	for name, content := range invalid {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, "bad.tiktoken")
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadBPE(path); err == nil {
+				t.Error("esperado erro")
+			}
+		})
+	}
+
+	t.Run("arquivo ausente", func(t *testing.T) {
+		if _, err := LoadBPE(filepath.Join(dir, "missing.tiktoken")); err == nil {
+			t.Error("esperado erro")
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package tokenizer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Tokenizer conta tokens de um texto segundo um vocabulário específico.
+// Implementações devem ser seguras para uso concorrente.
+type Tokenizer interface {
+	Name() string
+	Count(text string) int
+}
+
+// EstimatorName é o nome do estimador embutido (caracteres / 4).
+const EstimatorName = "estimate"
+
+// Estimator é um contador barato que aproxima um token a cada 4 caracteres,
+// a média observada em código com vocabulários BPE modernos.
+type Estimator struct{}
+
+func (Estimator) Name() string { return EstimatorName }
+
+func (Estimator) Count(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// New resolve um tokenizer pelo nome. Vazio ou "estimate" resultam no
+// Estimator; um caminho terminado em ".tiktoken" é carregado diretamente; e
+// qualquer outro nome (ex.: "cl100k_base", "o200k_base") é procurado como
+// <nome>.tiktoken dentro de vocabDir.
+func New(name, vocabDir string) (Tokenizer, error) {
+	name = strings.TrimSpace(name)
+
+	switch {
+	case name == "" || name == EstimatorName:
+		return Estimator{}, nil
+	case strings.HasSuffix(name, ".tiktoken"):
+		return LoadBPE(name)
+	default:
+		if vocabDir == "" {
+			return nil, fmt.Errorf("tokenizer %q: pasta de vocabulários não definida", name)
+		}
+		return LoadBPE(filepath.Join(vocabDir, name+".tiktoken"))
+	}
+}
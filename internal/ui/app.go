@@ -2,8 +2,10 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +20,9 @@ import (
 	"go-context-generator/internal/analyzer"
 	"go-context-generator/internal/config"
 	"go-context-generator/internal/generator"
+	"go-context-generator/internal/prompt"
+	"go-context-generator/internal/tokenizer"
+	"go-context-generator/internal/watch"
 )
 
 type App struct {
@@ -28,6 +33,7 @@ type App struct {
 	selectSrcBtn  widget.Clickable
 	selectDestBtn widget.Clickable
 	generateBtn   widget.Clickable
+	cancelBtn     widget.Clickable
 	settingsBtn   widget.Clickable
 
 	// UI State
@@ -36,20 +42,53 @@ type App struct {
 	lastRun        string
 	status         string
 	isProcessing   bool
+	isCancelling   bool
+	isWatching     bool // Geração concluída, observando alterações na origem
 	progress       float32
 	filesFound     int
 	filesGenerated int
+	problems       []analyzer.Diagnostic // Arquivos que não puderam ser interpretados na última geração
 
 	// Settings UI
-	showSettings   bool
-	removeComments widget.Bool
-	includeTests   widget.Bool
-	minifyOutput   widget.Bool
+	showSettings     bool
+	removeComments   widget.Bool
+	keepExportedDocs widget.Bool
+	includeTests     widget.Bool
+	includeBroken    widget.Bool
+	minifyOutput     widget.Bool
+	respectGitignore widget.Bool
+	pathPatterns     widget.Editor
+	outputMode       widget.Enum
+	outputFormat     widget.Enum
+	outputNaming     widget.Enum
+	exportJSON       widget.Bool
+	graphDOT         widget.Bool
+	graphMermaid     widget.Bool
+	graphJSON        widget.Bool
+	graphLevel       widget.Enum
+	omitTimestamps   widget.Bool
+	sliceDeps        widget.Bool
+	watchMode        widget.Bool
+	pruneOutputs     widget.Bool
+	mainView         widget.Enum
+	dependencyView   widget.Enum
+	tokenizerName    widget.Enum
+	maxTokens        widget.Editor
+	dependencyDepth  widget.Enum
+	buildTags        widget.Editor
+	workers          widget.Editor
+	templateName     widget.Editor
+	promptName       widget.Enum
+	promptPosition   widget.Enum
+	promptOptions    [][2]string   // Presets lidos ao abrir, mais "Nenhum"
+	buildTarget      widget.Editor // "GOOS/GOARCH"
+	settingsList     widget.List   // Rolagem do painel de configurações
 
 	// Background processing
-	ctx    context.Context
-	cancel context.CancelFunc
-	mu     sync.RWMutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	runCancel context.CancelFunc // Cancela a geração em andamento
+	mu        sync.RWMutex
 }
 
 func NewApp(theme *material.Theme) *App {
@@ -67,8 +106,57 @@ func NewApp(theme *material.Theme) *App {
 
 	// Aplicar configurações salvas
 	app.removeComments.Value = settings.RemoveComments
+	app.keepExportedDocs.Value = settings.KeepExportedDocs
 	app.includeTests.Value = settings.IncludeTests
+	app.includeBroken.Value = settings.IncludeUnparseable
 	app.minifyOutput.Value = settings.MinifyOutput
+	app.respectGitignore.Value = settings.RespectGitignore
+	app.outputMode.Value = settings.OutputMode
+	app.outputFormat.Value = settings.OutputFormat
+	app.outputNaming.Value = settings.OutputNaming
+	app.exportJSON.Value = settings.ExportJSON
+	for _, format := range settings.GraphFormats {
+		switch generator.GraphFormat(format) {
+		case generator.GraphDOT:
+			app.graphDOT.Value = true
+		case generator.GraphMermaid:
+			app.graphMermaid.Value = true
+		case generator.GraphJSON:
+			app.graphJSON.Value = true
+		}
+	}
+	app.graphLevel.Value = settings.GraphLevel
+	app.omitTimestamps.Value = settings.OmitTimestamps
+	app.sliceDeps.Value = settings.SliceDependencies
+	app.watchMode.Value = settings.Watch
+	app.pruneOutputs.Value = settings.PruneOutputs
+	app.mainView.Value = settings.MainView
+	app.dependencyView.Value = settings.DependencyView
+	app.tokenizerName.Value = settings.Tokenizer
+	app.dependencyDepth.Value = depthOption(settings.DependencyDepth)
+	app.maxTokens.SingleLine = true
+	app.maxTokens.Filter = "0123456789"
+	if settings.MaxTokensPerFile > 0 {
+		app.maxTokens.SetText(strconv.Itoa(settings.MaxTokensPerFile))
+	}
+	app.pathPatterns.SetText(strings.Join(settings.PathPatterns, "\n"))
+	app.workers.SingleLine = true
+	app.workers.Filter = "0123456789"
+	if settings.Workers > 0 {
+		app.workers.SetText(strconv.Itoa(settings.Workers))
+	}
+	app.templateName.SingleLine = true
+	app.templateName.SetText(settings.Template)
+	app.promptOptions = promptOptions(settings.Prompt)
+	app.promptName.Value = settings.Prompt
+	app.promptPosition.Value = settings.PromptPosition
+	app.buildTags.SingleLine = true
+	app.buildTags.SetText(strings.Join(settings.BuildTags, ","))
+	app.buildTarget.SingleLine = true
+	app.settingsList.Axis = layout.Vertical
+	if settings.GOOS != "" || settings.GOARCH != "" {
+		app.buildTarget.SetText(settings.GOOS + "/" + settings.GOARCH)
+	}
 
 	// Restaurar caminhos salvos se existirem
 	if settings.LastSrcPath != "" {
@@ -91,7 +179,9 @@ func (a *App) Run(w *app.Window) error {
 		e := w.NextEvent()
 		switch e := e.(type) {
 		case system.DestroyEvent:
-			a.saveSettings()
+			a.syncSettings()
+			settings := a.snapshotSettings()
+			settings.Save()
 			return e.Err
 		case system.FrameEvent:
 			gtx := layout.NewContext(&ops, e)
@@ -132,25 +222,148 @@ func (a *App) handleEvents(gtx layout.Context) {
 		a.showSettings = !a.showSettings
 	}
 
+	// Sincronizar configurações com os checkboxes
+	a.syncSettings()
+
 	// Botão de geração
 	if a.generateBtn.Clicked(gtx) && a.canGenerate() {
-		go a.generateContextFiles()
+		go a.generateContextFiles(a.snapshotSettings())
+	}
+
+	// Botão de cancelamento
+	if a.cancelBtn.Clicked(gtx) {
+		a.cancelRun()
 	}
+}
 
-	// Atualizar configurações quando mudarem
-	oldRemoveComments := a.removeComments.Value
-	oldIncludeTests := a.includeTests.Value
-	oldMinifyOutput := a.minifyOutput.Value
+// syncSettings copia os valores dos widgets para a.settings. A cópia é
+// montada fora do lock e publicada de uma vez com a.mu travado; a geração
+// trabalha sobre um retrato tirado com snapshotSettings.
+func (a *App) syncSettings() {
+	settings := *a.settings // Só esta goroutine escreve em a.settings
+	settings.RemoveComments = a.removeComments.Value
+	settings.KeepExportedDocs = a.keepExportedDocs.Value
+	settings.IncludeTests = a.includeTests.Value
+	settings.IncludeUnparseable = a.includeBroken.Value
+	settings.MinifyOutput = a.minifyOutput.Value
+	settings.RespectGitignore = a.respectGitignore.Value
+	settings.PathPatterns = parsePatternLines(a.pathPatterns.Text())
+	settings.OutputMode = a.outputMode.Value
+	settings.OutputFormat = a.outputFormat.Value
+	settings.OutputNaming = a.outputNaming.Value
+	settings.ExportJSON = a.exportJSON.Value
+	settings.GraphFormats = graphFormats(a.graphDOT.Value, a.graphMermaid.Value, a.graphJSON.Value)
+	settings.GraphLevel = a.graphLevel.Value
+	settings.OmitTimestamps = a.omitTimestamps.Value
+	settings.SliceDependencies = a.sliceDeps.Value
+	settings.Watch = a.watchMode.Value
+	settings.PruneOutputs = a.pruneOutputs.Value
+	settings.MainView = a.mainView.Value
+	settings.DependencyView = a.dependencyView.Value
+	settings.Tokenizer = a.tokenizerName.Value
+	settings.MaxTokensPerFile, _ = strconv.Atoi(a.maxTokens.Text())
+	settings.DependencyDepth = parseDepthOption(a.dependencyDepth.Value)
+	settings.Workers, _ = strconv.Atoi(a.workers.Text())
+	settings.Template = strings.TrimSpace(a.templateName.Text())
+	settings.Prompt = a.promptName.Value
+	settings.PromptPosition = a.promptPosition.Value
+	settings.BuildTags = parseBuildTags(a.buildTags.Text())
+	settings.GOOS, settings.GOARCH = parseBuildTarget(a.buildTarget.Text())
+
+	a.mu.Lock()
+	settings.LastSrcPath = a.srcPath
+	settings.LastDestPath = a.destPath
+	*a.settings = settings
+	a.mu.Unlock()
+}
+
+// snapshotSettings retorna uma cópia das configurações para uma geração. As
+// slices nunca são alteradas no lugar (syncSettings cria novas), então a
+// cópia rasa basta.
+func (a *App) snapshotSettings() config.Settings {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return *a.settings
+}
 
-	if a.removeComments.Value != oldRemoveComments {
-		a.settings.RemoveComments = a.removeComments.Value
+// graphFormats monta a lista de formatos do grafo marcados nas opções, em
+// uma slice nova, para que a configuração nunca fique com a lista pela metade.
+func graphFormats(dot, mermaid, json bool) []string {
+	var formats []string
+	for _, option := range []struct {
+		enabled bool
+		format  generator.GraphFormat
+	}{{dot, generator.GraphDOT}, {mermaid, generator.GraphMermaid}, {json, generator.GraphJSON}} {
+		if option.enabled {
+			formats = append(formats, string(option.format))
+		}
+	}
+	return formats
+}
+
+// promptOptions lista os presets de prompt da pasta de configuração e os
+// embutidos. Um prompt salvo que não é preset (um caminho) entra também,
+// para continuar selecionável.
+func promptOptions(current string) [][2]string {
+	// Sem a cópia na pasta, os presets embutidos continuam disponíveis
+	_ = prompt.Install(config.PromptDir())
+
+	options := [][2]string{{"", "Nenhum"}}
+	found := current == ""
+	for _, name := range prompt.Names(config.PromptDir()) {
+		options = append(options, [2]string{name, name})
+		found = found || name == current
+	}
+	if !found {
+		options = append(options, [2]string{current, filepath.Base(current)})
+	}
+	return options
+}
+
+// parsePatternLines converte o texto do editor de padrões (um por linha) na
+// lista ordenada usada pelo scanner.
+func parsePatternLines(text string) []string {
+	var patterns []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			patterns = append(patterns, line)
+		}
+	}
+	return patterns
+}
+
+// depthOption converte a profundidade salva no valor do seletor ("1".."3" ou "all").
+func depthOption(depth int) string {
+	switch {
+	case depth == generator.DepthAll:
+		return "all"
+	case depth > 3:
+		return "3"
+	case depth < 1:
+		return "1"
 	}
-	if a.includeTests.Value != oldIncludeTests {
-		a.settings.IncludeTests = a.includeTests.Value
+	return strconv.Itoa(depth)
+}
+
+func parseDepthOption(value string) int {
+	if value == "all" {
+		return generator.DepthAll
 	}
-	if a.minifyOutput.Value != oldMinifyOutput {
-		a.settings.MinifyOutput = a.minifyOutput.Value
+	if depth, err := strconv.Atoi(value); err == nil && depth > 0 {
+		return depth
 	}
+	return 1
+}
+
+// parseBuildTags separa as build tags digitadas, por vírgula ou espaço.
+func parseBuildTags(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool { return r == ',' || r == ' ' })
+}
+
+// parseBuildTarget separa "GOOS/GOARCH"; partes vazias usam o ambiente.
+func parseBuildTarget(text string) (goos, goarch string) {
+	goos, goarch, _ = strings.Cut(strings.TrimSpace(text), "/")
+	return strings.TrimSpace(goos), strings.TrimSpace(goarch)
 }
 
 func (a *App) canGenerate() bool {
@@ -159,93 +372,291 @@ func (a *App) canGenerate() bool {
 	return a.srcPath != "" && a.destPath != "" && !a.isProcessing
 }
 
-func (a *App) generateContextFiles() {
+// cancelRun pede a interrupção da geração em andamento, se houver.
+func (a *App) cancelRun() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.runCancel == nil || a.isCancelling {
+		return
+	}
+	a.isCancelling = true
+	a.status = "⚠️ Cancelando..."
+	a.runCancel()
+}
+
+// generateContextFiles gera os contextos com o retrato das configurações
+// tirado no clique; alterações posteriores valem para a próxima geração.
+func (a *App) generateContextFiles(settings config.Settings) {
+	ctx, cancel := context.WithCancel(a.ctx)
+
 	a.mu.Lock()
 	a.isProcessing = true
+	a.isCancelling = false
+	a.runCancel = cancel
 	a.progress = 0
 	a.filesFound = 0
 	a.filesGenerated = 0
+	a.problems = nil
 	a.status = "🔍 Escaneando arquivos de código fonte..."
 	a.mu.Unlock()
 
 	defer func() {
+		cancel()
+
 		a.mu.Lock()
 		a.isProcessing = false
-		a.lastRun = time.Now().Format("15:04 - 02/01/2006")
-
-		if a.filesGenerated > 0 {
-			a.progress = 1.0
-			a.status = fmt.Sprintf("✅ Concluído! %d arquivos de contexto gerados", a.filesGenerated)
-		}
+		a.isCancelling = false
+		a.isWatching = false
+		a.runCancel = nil
 		a.mu.Unlock()
 	}()
 
 	// Salvar configurações atuais
-	a.saveSettings()
+	settings.Save()
+
+	genConfig, err := generatorConfig(settings)
+	if err != nil {
+		a.mu.Lock()
+		a.status = "❌ " + err.Error()
+		a.mu.Unlock()
+		return
+	}
 
-	// Criar scanner com configurações
+	// O mesmo scanner é usado em todas as gerações do modo observação, para
+	// reaproveitar os arquivos que não mudaram
 	scanner := analyzer.NewScanner(analyzer.ScanConfig{
-		IncludeTests:   a.settings.IncludeTests,
-		RemoveComments: a.settings.RemoveComments,
-		MinifyOutput:   a.settings.MinifyOutput,
+		IncludeTests:     settings.IncludeTests,
+		RemoveComments:   settings.RemoveComments,
+		KeepExportedDocs: settings.KeepExportedDocs,
+		MinifyOutput:     settings.MinifyOutput,
+		RespectGitignore: settings.RespectGitignore,
+		PathPatterns:     settings.PathPatterns,
+		GOOS:             settings.GOOS,
+		GOARCH:           settings.GOARCH,
+		BuildTags:        settings.BuildTags,
+
+		SliceDependencies:  settings.SliceDependencies,
+		IncludeUnparseable: settings.IncludeUnparseable,
+		Workers:            settings.Workers,
+	})
+
+	if !a.runGeneration(ctx, scanner, genConfig, nil) || !settings.Watch {
+		return
+	}
+
+	a.mu.Lock()
+	a.isWatching = true
+	a.status = a.watchingStatus()
+	a.mu.Unlock()
+
+	// Cada lote regenera apenas os contextos afetados pelas alterações desde
+	// a última geração concluída; depois de uma falha, a próxima é completa
+	incremental := true
+	var pending []string
+	err = watch.Run(ctx, watch.Config{
+		Root: settings.LastSrcPath,
+		Skip: scanner.ShouldSkip,
+		OnChange: func(paths []string) {
+			pending = append(pending, paths...)
+			changed := pending
+			if !incremental {
+				changed = nil
+			}
+			incremental = a.runGeneration(ctx, scanner, genConfig, changed)
+			if incremental {
+				pending = nil
+				a.mu.Lock()
+				a.status = a.watchingStatus()
+				a.mu.Unlock()
+			}
+		},
 	})
 
+	a.mu.Lock()
+	if err != nil {
+		a.status = "❌ Erro ao observar alterações: " + err.Error()
+	} else if !strings.Contains(a.status, "❌") {
+		a.status = "⚠️ Observação encerrada. Última geração às " + a.lastRun
+	}
+	a.mu.Unlock()
+}
+
+// watchingStatus descreve o modo observação; chamado com a.mu travado.
+func (a *App) watchingStatus() string {
+	return fmt.Sprintf("🔄 Observando %s: os contextos afetados são regenerados a cada alteração (última às %s)", filepath.Base(a.srcPath), a.lastRun)
+}
+
+// generatorConfig monta a configuração do gerador a partir de um retrato das
+// configurações (ver snapshotSettings).
+func generatorConfig(settings config.Settings) (generator.Config, error) {
+	outputMode, err := generator.ParseOutputMode(settings.OutputMode)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	outputFormat, err := generator.ParseFormat(settings.OutputFormat)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	naming, err := generator.ParseNamingScheme(settings.OutputNaming)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	mainView, err := generator.ParseCodeView(settings.MainView)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	timestamp, _, err := generator.SourceDateEpoch()
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	dependencyView, err := generator.ParseCodeView(settings.DependencyView)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	tok, err := tokenizer.New(settings.Tokenizer, config.TokenizerDir())
+	if err != nil {
+		return generator.Config{}, fmt.Errorf("erro ao carregar tokenizer: %w", err)
+	}
+
+	var tmpl *generator.Template
+	if settings.Template != "" {
+		if tmpl, err = generator.LoadTemplate(settings.Template); err != nil {
+			return generator.Config{}, err
+		}
+	}
+
+	var taskPrompt *prompt.Prompt
+	if settings.Prompt != "" {
+		if taskPrompt, err = prompt.Load(settings.Prompt, config.PromptDir()); err != nil {
+			return generator.Config{}, err
+		}
+	}
+
+	promptPosition, err := generator.ParsePromptPosition(settings.PromptPosition)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	graphFormats, err := generator.ParseGraphFormats(strings.Join(settings.GraphFormats, ","))
+	if err != nil {
+		return generator.Config{}, err
+	}
+	graphLevel, err := generator.ParseGraphLevel(settings.GraphLevel)
+	if err != nil {
+		return generator.Config{}, err
+	}
+
+	genConfig := generator.Config{
+		OutputDir:        settings.LastDestPath,
+		SourceDir:        settings.LastSrcPath,
+		RemoveComments:   settings.RemoveComments,
+		MinifyOutput:     settings.MinifyOutput,
+		Mode:             outputMode,
+		Format:           outputFormat,
+		Naming:           naming,
+		OmitTimestamps:   settings.OmitTimestamps,
+		Timestamp:        timestamp,
+		Template:         tmpl,
+		Prompt:           taskPrompt,
+		PromptPosition:   promptPosition,
+		ExportJSON:       settings.ExportJSON,
+		GraphFormats:     graphFormats,
+		GraphLevel:       graphLevel,
+		Tokenizer:        tok,
+		MaxTokensPerFile: settings.MaxTokensPerFile,
+		DependencyDepth:  settings.DependencyDepth,
+		MainView:         mainView,
+		DependencyView:   dependencyView,
+
+		SliceDependencies: settings.SliceDependencies,
+		Workers:           settings.Workers,
+		Prune:             settings.PruneOutputs,
+	}
+	if err := genConfig.CheckMode(); err != nil {
+		return generator.Config{}, err
+	}
+	return genConfig, nil
+}
+
+// runGeneration escaneia a origem e grava os contextos, atualizando o status;
+// changed restringe a geração aos contextos afetados (ver
+// generator.Generator.SetChangedPaths). Retorna false se a geração falhou ou
+// foi cancelada.
+func (a *App) runGeneration(ctx context.Context, scanner *analyzer.Scanner, genConfig generator.Config, changed []string) bool {
 	// Escanear arquivos
-	files, err := scanner.ScanDirectory(a.srcPath)
+	files, err := scanner.ScanDirectory(ctx, genConfig.SourceDir)
+	if errors.Is(err, context.Canceled) {
+		a.mu.Lock()
+		a.status = "⚠️ Escaneamento cancelado"
+		a.mu.Unlock()
+		return false
+	}
 	if err != nil {
 		a.mu.Lock()
 		a.status = "❌ Erro ao escanear arquivos: " + err.Error()
 		a.mu.Unlock()
-		return
+		return false
 	}
 
 	if len(files) == 0 {
 		a.mu.Lock()
 		a.status = "⚠️ Nenhum arquivo de código fonte encontrado na pasta selecionada"
 		a.mu.Unlock()
-		return
+		return false
 	}
 
 	a.mu.Lock()
+	a.progress = 0
 	a.filesFound = len(files)
+	a.filesGenerated = 0
+	a.problems = scanner.Diagnostics()
 	a.status = fmt.Sprintf("🔄 Encontrados %d arquivos de código fonte. Gerando contextos...", len(files))
 	a.mu.Unlock()
 
 	// Gerar arquivos de contexto
-	gen := generator.NewGenerator(generator.Config{
-		OutputDir:      a.destPath,
-		SourceDir:      a.srcPath,
-		RemoveComments: a.settings.RemoveComments,
-		MinifyOutput:   a.settings.MinifyOutput,
-	})
-
+	gen := generator.NewGenerator(genConfig)
+	gen.SetSkippedPaths(scanner.SkippedPaths())
+	gen.SetDiagnostics(scanner.Diagnostics())
+	gen.SetChangedPaths(changed)
 	gen.SetProgressCallback(func(current, total int) {
 		a.mu.Lock()
 		if total > 0 {
 			a.progress = float32(current) / float32(total)
 		}
 		a.filesGenerated = current
-		if current < total {
+		if current < total && !a.isCancelling {
 			a.status = fmt.Sprintf("⚡ Processando... %d/%d arquivos", current, total)
 		}
 		a.mu.Unlock()
 	})
 
-	if err := gen.GenerateContextFiles(files); err != nil {
-		a.mu.Lock()
-		a.status = "❌ Erro na geração: " + err.Error()
-		a.mu.Unlock()
-		return
-	}
-}
+	err = gen.GenerateContextFiles(ctx, files)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.lastRun = time.Now().Format("15:04 - 02/01/2006")
 
-func (a *App) saveSettings() {
-	// Atualizar paths nas configurações
-	a.settings.LastSrcPath = a.srcPath
-	a.settings.LastDestPath = a.destPath
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			a.status = "⚠️ Geração cancelada. A pasta de destino não foi alterada"
+		} else {
+			a.status = "❌ Erro na geração: " + err.Error()
+		}
+		return false
+	}
 
-	// Salvar configurações
-	a.settings.Save()
+	a.progress = 1.0
+	a.status = fmt.Sprintf("✅ Concluído! %d documentos gravados", gen.Written())
+	if reused := gen.Reused(); reused > 0 {
+		a.status += fmt.Sprintf(" (%d sem alterações)", reused)
+	}
+	return true
 }
 
 func (a *App) getStatusColor() ColorRGBA {
@@ -116,14 +116,14 @@ func (c Card) Layout(gtx layout.Context, w layout.Widget) layout.Dimensions {
 
 		// This is tricky. Simpler: draw the border with clip.Stroke on the original cardRect
 
-		clip.Stroke{
+		borderClip := clip.Stroke{
 			Path:  clip.RRect{Rect: cardRect, SE: radiusPx, SW: radiusPx, NW: radiusPx, NE: radiusPx}.Path(gtx.Ops),
 			Width: borderWidthPx,
 		}.Op().Push(gtx.Ops)
 
 		paint.ColorOp{Color: borderColor}.Add(gtx.Ops)
 		paint.PaintOp{}.Add(gtx.Ops)
-		bgClip.Pop()
+		borderClip.Pop() // Desempilhar o clip da borda, não o do fundo (já desempilhado)
 	}
 
 	// Add the recorded content operations (drawn on top)
@@ -13,6 +13,10 @@ import (
 	"gioui.org/unit"
 	"gioui.org/widget"
 	"gioui.org/widget/material"
+
+	"go-context-generator/internal/analyzer"
+	"go-context-generator/internal/config"
+	"go-context-generator/internal/generator"
 )
 
 type ColorRGBA = color.NRGBA
@@ -199,6 +203,7 @@ func (a *App) layoutStatusSection(gtx layout.Context) layout.Dimensions {
 			progress := a.progress
 			filesFound := a.filesFound
 			filesGenerated := a.filesGenerated
+			problems := a.problems
 			a.mu.RUnlock()
 
 			statusColor := a.getStatusColor() // Sua lógica para cor de status é boa
@@ -277,6 +282,8 @@ func (a *App) layoutStatusSection(gtx layout.Context) layout.Dimensions {
 									label.Alignment = text.End // Alinhar à direita sob a barra
 									return label.Layout(gtx)
 								}),
+								layout.Rigid(layout.Spacer{Height: smallPadding}.Layout),
+								layout.Rigid(a.layoutCancelButton),
 							)
 						})
 					}
@@ -288,11 +295,85 @@ func (a *App) layoutStatusSection(gtx layout.Context) layout.Dimensions {
 					}
 					return layout.Dimensions{}
 				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					if !isProcessing && len(problems) > 0 {
+						return layout.Inset{Top: mediumPadding}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return a.layoutProblems(gtx, problems)
+						})
+					}
+					return layout.Dimensions{}
+				}),
 			)
 		})
 	})
 }
 
+// Máximo de problemas listados no status; os demais estão na visão geral
+const maxListedProblems = 8
+
+// layoutProblems lista os arquivos que não puderam ser interpretados.
+func (a *App) layoutProblems(gtx layout.Context, problems []analyzer.Diagnostic) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			title := material.Body2(a.theme, fmt.Sprintf("⚠️ Problemas (%d)", len(problems)))
+			title.Color = ColorWarning
+			return layout.Inset{Bottom: smallPadding}.Layout(gtx, title.Layout)
+		}),
+	}
+
+	for i, problem := range problems {
+		if i == maxListedProblems {
+			more := fmt.Sprintf("… e mais %d (veja a visão geral do projeto)", len(problems)-maxListedProblems)
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				label := material.Caption(a.theme, more)
+				label.Color = ColorTextMuted
+				return label.Layout(gtx)
+			}))
+			break
+		}
+
+		action := "ignorado"
+		if problem.Included {
+			action = "incluído como código bruto"
+		}
+		line := fmt.Sprintf("%s — %s (%s)", problem.Position(), problem.Reason, action)
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			label := material.Caption(a.theme, line)
+			label.Color = ColorTextSecondary
+			return label.Layout(gtx)
+		}))
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
+
+func (a *App) layoutCancelButton(gtx layout.Context) layout.Dimensions {
+	a.mu.RLock()
+	isCancelling := a.isCancelling
+	isWatching := a.isWatching
+	a.mu.RUnlock()
+
+	btnText := "⏹️ Cancelar"
+	if isWatching {
+		btnText = "⏹️ Parar Observação"
+	}
+	clickable := &a.cancelBtn
+	if isCancelling {
+		btnText = "⏳ Cancelando..."
+		clickable = &widget.Clickable{} // Desabilitar clique
+	}
+
+	btn := material.Button(a.theme, clickable, btnText)
+	btn.Background = ColorDanger
+	btn.Color = ColorTextOnPrimary
+	btn.CornerRadius = smallRadius
+	if isCancelling {
+		btn.Background = ColorBorder
+		btn.Color = ColorTextMuted
+	}
+	return btn.Layout(gtx)
+}
+
 func (a *App) layoutStats(gtx layout.Context) layout.Dimensions {
 	a.mu.RLock()
 	filesFound := a.filesFound
@@ -414,20 +495,143 @@ func (a *App) layoutSettings(gtx layout.Context) layout.Dimensions {
 					)
 				}),
 				layout.Rigid(layout.Spacer{Height: xlargePadding}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return a.layoutCheckboxItem(gtx, &a.removeComments, "Remover Comentários", "Exclui a maioria dos comentários, preservando a documentação essencial (godoc).")
-				}),
-				layout.Rigid(layout.Spacer{Height: largePadding}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return a.layoutCheckboxItem(gtx, &a.includeTests, "Incluir Arquivos de Teste", "Processa arquivos de teste (ex: *_test.*, *.spec.*) juntamente com o código fonte.")
-				}),
-				layout.Rigid(layout.Spacer{Height: largePadding}.Layout),
-				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
-					return a.layoutCheckboxItem(gtx, &a.minifyOutput, "Otimizar Saída para IA (Minify)", "Remove espaços em branco e quebras de linha desnecessários. A eficácia varia por linguagem.")
-				}),
-				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions { // Espaço flexível para empurrar o botão para baixo
-					return layout.Spacer{Height: xlargePadding}.Layout(gtx)
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					// As opções não cabem em uma janela pequena: só elas rolam,
+					// o título e o botão ficam sempre visíveis
+					rows := []layout.Widget{
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.removeComments, "Remover Comentários", "Exclui os comentários com base na AST do arquivo. Diretivas (//go:, +build) e o preâmbulo cgo são sempre mantidos.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.keepExportedDocs, "Manter Documentação Exportada", "Ao remover comentários, preserva a documentação do pacote e dos identificadores exportados (godoc).")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.includeTests, "Incluir Arquivos de Teste", "Processa arquivos de teste (ex: *_test.*, *.spec.*) juntamente com o código fonte.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.includeBroken, "Incluir Arquivos com Erros", "Arquivos com erro de sintaxe ou marcadores de conflito de merge entram no contexto como código bruto, marcados como unparseable. Eles são sempre listados em Problemas.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.minifyOutput, "Otimizar Saída para IA (Minify)", "Remove o alinhamento de colunas e as linhas em branco dentro de blocos. O código resultante continua válido.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.respectGitignore, "Respeitar .gitignore", "Ignora os caminhos listados nos arquivos .gitignore do projeto. O .contextignore é sempre respeitado.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						a.layoutPatternsEditor,
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.outputMode, "Modo de Saída", "Um contexto por arquivo Go, um por pacote, ou um único documento com todo o projeto em ordem de dependência.",
+								[][2]string{{"files", "Um arquivo por .go"}, {"packages", "Um por pacote"}, {"bundle", "Bundle único"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.dependencyDepth, "Profundidade das Dependências", "Quantos níveis de imports locais entram em cada contexto. Cada dependência indica a cadeia de imports que a trouxe.",
+								[][2]string{{"1", "Apenas diretas"}, {"2", "2 níveis"}, {"3", "3 níveis"}, {"all", "Fecho completo"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.sliceDeps, "Recorte por Símbolos", "Das dependências, inclui apenas as funções, tipos, métodos, constantes e variáveis realmente referenciados (checagem de tipos com go/types).")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.watchMode, "Observar Alterações", "Depois de gerar, continua observando a pasta de origem e regenera apenas os contextos afetados a cada alteração. Use Cancelar para parar.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.pruneOutputs, "Limpar Contextos Antigos", "Apaga do destino todo *_CONTEXT.* que a geração não produziu, mesmo de versões ou modos anteriores. Recusa pastas que não parecem uma geração anterior.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.mainView, "Código Principal", "Código completo ou esqueleto (tipos, assinaturas e documentação, sem corpos de funções) do arquivo, pacote ou bundle.",
+								[][2]string{{"full", "Completo"}, {"skeleton", "Esqueleto"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.dependencyView, "Código das Dependências", "Esqueletos reduzem muito o tamanho dos contextos em projetos grandes, mantendo a API visível.",
+								[][2]string{{"full", "Completo"}, {"skeleton", "Esqueleto"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.outputFormat, "Formato de Saída", "Texto puro (.txt), Markdown (.md) com tabelas e blocos de código ```go, ou XML (.xml) com cada parte delimitada por tags.",
+								[][2]string{{"text", "Texto"}, {"markdown", "Markdown"}, {"xml", "XML"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.outputNaming, "Nomes dos Contextos", "Nomes achatados na raiz do destino, com um hash curto do caminho que evita colisões, ou a mesma estrutura de pastas da origem.",
+								[][2]string{{"flat", "Achatados"}, {"mirror", "Pastas espelhadas"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.omitTimestamps, "Omitir Data da Geração", "Remove a data dos documentos, para que a mesma entrada produza exatamente os mesmos arquivos (útil para versionar os contextos e revisar diffs).")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.exportJSON, "Exportar JSON Estruturado", "Grava também project.json (estatísticas e grafo de dependências) e files.jsonl (um registro por arquivo) para outras ferramentas.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.graphDOT, "Grafo Graphviz (DOT)", "Grava dependencies.dot, com os arquivos agrupados por pasta e os ciclos de import em vermelho.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.graphMermaid, "Grafo Mermaid", "Grava dependencies.mmd e, no formato Markdown, inclui o diagrama na visão geral.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutCheckboxItem(gtx, &a.graphJSON, "Grafo JSON", "Grava dependencies.json: a lista de adjacência e os ciclos, para outras ferramentas.")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.graphLevel, "Nós do Grafo", "Um nó por arquivo ou os arquivos agrupados em pacotes.",
+								[][2]string{{"files", "Arquivos"}, {"packages", "Pacotes"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.tokenizerName, "Contagem de Tokens", "Estimativa rápida (caracteres/4) ou BPE exato com o vocabulário .tiktoken salvo em "+config.TokenizerDir()+".",
+								[][2]string{{"estimate", "Estimativa"}, {"cl100k_base", "cl100k_base"}, {"o200k_base", "o200k_base"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutTextField(gtx, &a.maxTokens, "Orçamento de Tokens por Contexto", "Acima do limite, o código das dependências menos relevantes é descartado. Vazio ou 0 desativa.", "0")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutTextField(gtx, &a.templateName, "Template de Saída", "Nome de um template embutido ("+strings.Join(generator.BuiltinTemplates(), ", ")+") ou caminho de um arquivo text/template. Vazio usa o formato escolhido.", "minimal")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.promptName, "Prompt de Tarefa", "Instrução inserida em cada contexto, com o arquivo, o pacote e as dependências preenchidos. Arquivos .txt em "+config.PromptDir()+" acrescentam ou substituem presets (reabra o programa para vê-los).",
+								a.promptOptions)
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutOptionGroup(gtx, &a.promptPosition, "Posição do Prompt", "No início do contexto, ou no fim, logo antes da sua pergunta.",
+								[][2]string{{"top", "Início"}, {"bottom", "Fim"}})
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutTextField(gtx, &a.workers, "Processamento Paralelo", "Número de goroutines para interpretar arquivos e gerar contextos. Vazio ou 0 usa todos os núcleos.", "0")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutTextField(gtx, &a.buildTags, "Build Tags", "Tags consideradas ao resolver quais arquivos compõem cada pacote importado, separadas por vírgula.", "integration,debug")
+						},
+						layout.Spacer{Height: largePadding}.Layout,
+						func(gtx layout.Context) layout.Dimensions {
+							return a.layoutTextField(gtx, &a.buildTarget, "Plataforma Alvo", "GOOS/GOARCH usados nas restrições de build das dependências. Vazio usa o sistema atual.", "linux/amd64")
+						},
+					}
+					return material.List(a.theme, &a.settingsList).Layout(gtx, len(rows), func(gtx layout.Context, i int) layout.Dimensions {
+						return rows[i](gtx)
+					})
 				}),
+				layout.Rigid(layout.Spacer{Height: xlargePadding}.Layout),
 				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 					// Botão Voltar/Concluído alinhado à direita
 					return layout.E.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
@@ -478,6 +682,105 @@ func (a *App) layoutCheckboxItem(gtx layout.Context, checkbox *widget.Bool, titl
 	)
 }
 
+// layoutOptionGroup é um helper para grupos de opções exclusivas (radio buttons)
+func (a *App) layoutOptionGroup(gtx layout.Context, enum *widget.Enum, title, description string, options [][2]string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			titleLabel := material.Label(a.theme, unit.Sp(16), title)
+			titleLabel.Color = ColorTextPrimary
+			return titleLabel.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			descLabel := material.Caption(a.theme, description)
+			descLabel.Color = ColorTextSecondary
+			return descLabel.Layout(gtx)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			children := make([]layout.FlexChild, 0, len(options))
+			for _, option := range options {
+				key, label := option[0], option[1]
+				children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					rb := material.RadioButton(a.theme, enum, key, label)
+					rb.Color = ColorTextPrimary
+					rb.IconColor = ColorPrimary
+					return layout.Inset{Right: mediumPadding}.Layout(gtx, rb.Layout)
+				}))
+			}
+			return layout.Flex{Alignment: layout.Middle}.Layout(gtx, children...)
+		}),
+	)
+}
+
+// layoutTextField é um helper para campos de texto de uma linha com título e descrição
+func (a *App) layoutTextField(gtx layout.Context, editor *widget.Editor, title, description, hint string) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			titleLabel := material.Label(a.theme, unit.Sp(16), title)
+			titleLabel.Color = ColorTextPrimary
+			return titleLabel.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			descLabel := material.Caption(a.theme, description)
+			descLabel.Color = ColorTextSecondary
+			return descLabel.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: smallPadding}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return Card{
+				Color:        ColorBackground,
+				CornerRadius: smallRadius,
+				BorderWidth:  unit.Dp(1),
+			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(smallPadding).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Dp(unit.Dp(160))
+					ed := material.Editor(a.theme, editor, hint)
+					ed.Color = ColorTextPrimary
+					ed.HintColor = ColorTextMuted
+					ed.TextSize = unit.Sp(14)
+					return ed.Layout(gtx)
+				})
+			})
+		}),
+	)
+}
+
+// layoutPatternsEditor mostra o editor dos padrões de inclusão/exclusão, um por linha
+func (a *App) layoutPatternsEditor(gtx layout.Context) layout.Dimensions {
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			titleLabel := material.Label(a.theme, unit.Sp(16), "Padrões de Inclusão/Exclusão")
+			titleLabel.Color = ColorTextPrimary
+			return titleLabel.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(4)}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			descLabel := material.Caption(a.theme, "Um glob por linha: \"+build/**\" inclui, \"-**/zz_generated.*.go\" exclui. O último padrão que casa vence e sobrepõe as regras padrão.")
+			descLabel.Color = ColorTextSecondary
+			return descLabel.Layout(gtx)
+		}),
+		layout.Rigid(layout.Spacer{Height: smallPadding}.Layout),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return Card{
+				Color:        ColorBackground,
+				CornerRadius: smallRadius,
+				BorderWidth:  unit.Dp(1),
+			}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.UniformInset(smallPadding).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					gtx.Constraints.Min.X = gtx.Constraints.Max.X
+					gtx.Constraints.Min.Y = gtx.Dp(unit.Dp(72))
+					editor := material.Editor(a.theme, &a.pathPatterns, "+build/**\n-**/zz_generated.*.go")
+					editor.Color = ColorTextPrimary
+					editor.HintColor = ColorTextMuted
+					editor.TextSize = unit.Sp(14)
+					return editor.Layout(gtx)
+				})
+			})
+		}),
+	)
+}
+
 func (a *App) layoutFooter(gtx layout.Context) layout.Dimensions {
 	return layout.Inset{Top: smallPadding}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 		return layout.Flex{Alignment: layout.Middle, Spacing: layout.SpaceBetween}.Layout(gtx,
@@ -0,0 +1,176 @@
+// Package watch observa uma árvore de código e agrupa as alterações em lotes,
+// para que os contextos sejam regenerados uma vez por rajada de eventos.
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce é o silêncio esperado depois do último evento antes de
+// entregar o lote: editores costumam gravar um arquivo em vários passos.
+const DefaultDebounce = 300 * time.Millisecond
+
+// Config descreve o que observar e como entregar as alterações.
+type Config struct {
+	Root     string
+	Debounce time.Duration // 0 usa DefaultDebounce
+
+	// Skip aplica as regras de exclusão do escaneamento; diretórios
+	// excluídos não são observados e arquivos excluídos não geram lotes
+	Skip func(path string, isDir bool) bool
+
+	// OnChange recebe os caminhos alterados, ordenados e sem repetição. É
+	// chamado na goroutine de Run; eventos que chegam durante a chamada
+	// formam o próximo lote.
+	OnChange func(paths []string)
+}
+
+// projectFiles são arquivos que não são .go mas mudam a resolução de
+// dependências ou as regras de exclusão.
+var projectFiles = map[string]bool{
+	"go.mod":         true,
+	"go.work":        true,
+	".gitignore":     true,
+	".contextignore": true,
+}
+
+// Run observa config.Root até ctx ser cancelado, quando retorna nil. Novos
+// diretórios passam a ser observados assim que criados.
+func Run(ctx context.Context, config Config) error {
+	if config.Debounce <= 0 {
+		config.Debounce = DefaultDebounce
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	w := &treeWatcher{config: config, watcher: watcher, dirs: make(map[string]bool)}
+	if err := w.addTree(config.Root); err != nil {
+		return err
+	}
+
+	pending := make(map[string]bool)
+	timer := time.NewTimer(config.Debounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if w.handle(event) {
+				pending[event.Name] = true
+				timer.Reset(config.Debounce)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			// Com a fila do sistema cheia, eventos foram perdidos: regenerar
+			// a partir da raiz
+			if errors.Is(err, fsnotify.ErrEventOverflow) {
+				pending[config.Root] = true
+				timer.Reset(config.Debounce)
+				continue
+			}
+			return err
+
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for path := range pending {
+				paths = append(paths, path)
+			}
+			sort.Strings(paths)
+			pending = make(map[string]bool)
+			config.OnChange(paths)
+		}
+	}
+}
+
+type treeWatcher struct {
+	config  Config
+	watcher *fsnotify.Watcher
+	dirs    map[string]bool // Diretórios observados
+}
+
+// addTree observa dir e seus subdiretórios não excluídos.
+func (w *treeWatcher) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Diretório removido durante o percurso
+			if errors.Is(err, fs.ErrNotExist) && path != dir {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != w.config.Root && w.config.Skip != nil && w.config.Skip(path, true) {
+			return fs.SkipDir
+		}
+		if err := w.watcher.Add(path); err != nil {
+			return err
+		}
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+// handle decide se o evento altera o contexto, observando os diretórios
+// recém-criados.
+func (w *treeWatcher) handle(event fsnotify.Event) bool {
+	if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+			if w.config.Skip != nil && w.config.Skip(event.Name, true) {
+				return false
+			}
+			// Arquivos criados junto com o diretório não geram eventos
+			// próprios, então o diretório inteiro conta como alterado
+			w.addTree(event.Name)
+			return true
+		}
+	}
+
+	// Remoção ou renomeação de um diretório observado: o sistema descarta a
+	// observação sozinho
+	if w.dirs[event.Name] && (event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename)) {
+		prefix := event.Name + string(filepath.Separator)
+		for dir := range w.dirs {
+			if dir == event.Name || strings.HasPrefix(dir, prefix) {
+				delete(w.dirs, dir)
+			}
+		}
+		return true
+	}
+
+	name := filepath.Base(event.Name)
+	if projectFiles[name] {
+		return true
+	}
+	if filepath.Ext(name) != ".go" {
+		return false
+	}
+	return w.config.Skip == nil || !w.config.Skip(event.Name, false)
+}
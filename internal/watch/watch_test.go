@@ -0,0 +1,130 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"go-context-generator/internal/analyzer"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestRunCoalescesAndFilters grava uma rajada de arquivos, parte deles
+// excluída pelas regras do scanner, e exige um único lote com os demais.
+func TestRunCoalescesAndFilters(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "go.mod"), "module example.com/w\n")
+	writeFile(t, filepath.Join(root, ".gitignore"), "gen/\n")
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "gen", "old.go"), "package gen\n")
+	writeFile(t, filepath.Join(root, "vendor", "dep", "dep.go"), "package dep\n")
+
+	scanner := analyzer.NewScanner(analyzer.ScanConfig{RespectGitignore: true})
+	if _, err := scanner.ScanDirectory(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+
+	const debounce = 200 * time.Millisecond
+	batches := make(chan []string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, Config{
+			Root:     root,
+			Debounce: debounce,
+			Skip:     scanner.ShouldSkip,
+			OnChange: func(paths []string) { batches <- paths },
+		})
+	}()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Run retornou %v", err)
+		}
+	}()
+	time.Sleep(debounce) // Esperar as observações serem registradas
+
+	// Eventos com intervalo menor que o debounce formam um único lote
+	writeFile(t, filepath.Join(root, "a.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "main.go"), "package main\n\nfunc main() {}\n")
+	time.Sleep(debounce / 4)
+	writeFile(t, filepath.Join(root, "b.go"), "package main\n")
+
+	// Excluídos: fora de .go, testes, .gitignore e diretórios embutidos
+	writeFile(t, filepath.Join(root, "notes.txt"), "x\n")
+	writeFile(t, filepath.Join(root, "a_test.go"), "package main\n")
+	writeFile(t, filepath.Join(root, "gen", "new.go"), "package gen\n")
+	writeFile(t, filepath.Join(root, "vendor", "dep", "dep.go"), "package dep\n\nvar X int\n")
+
+	want := []string{
+		filepath.Join(root, "a.go"),
+		filepath.Join(root, "b.go"),
+		filepath.Join(root, "main.go"),
+	}
+	select {
+	case got := <-batches:
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("lote = %v, esperado %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nenhum lote entregue")
+	}
+
+	select {
+	case got := <-batches:
+		t.Errorf("lote extra: %v", got)
+	case <-time.After(3 * debounce):
+	}
+}
+
+func TestHandleFiltersIgnoredPaths(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, ".contextignore"), "generated_*.go\n")
+
+	scanner := analyzer.NewScanner(analyzer.ScanConfig{})
+	if _, err := scanner.ScanDirectory(context.Background(), root); err != nil {
+		t.Fatal(err)
+	}
+	w := &treeWatcher{config: Config{Root: root, Skip: scanner.ShouldSkip}, dirs: make(map[string]bool)}
+
+	for name, want := range map[string]bool{
+		"x.go":             true,
+		"go.work":          true,
+		".contextignore":   true,
+		"README.md":        false,
+		"doc.go":           false,
+		"x_test.go":        false,
+		"generated_api.go": false,
+	} {
+		event := fsnotify.Event{Name: filepath.Join(root, name), Op: fsnotify.Write}
+		if got := w.handle(event); got != want {
+			t.Errorf("handle(%s) = %v, esperado %v", name, got, want)
+		}
+	}
+
+	// Diretório excluído criado durante a observação
+	skipped := filepath.Join(root, "node_modules")
+	if err := os.Mkdir(skipped, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if w.handle(fsnotify.Event{Name: skipped, Op: fsnotify.Create}) {
+		t.Error("diretório excluído gerou um lote")
+	}
+	if w.dirs[skipped] {
+		t.Error("diretório excluído passou a ser observado")
+	}
+}
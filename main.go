@@ -2,35 +2,18 @@ package main
 
 import (
 	_ "embed"
-	"log"
 	"os"
 
-	"gioui.org/app"
-	"gioui.org/font/gofont"
-	"gioui.org/text"
-	"gioui.org/widget/material"
-
-	"go-context-generator/internal/ui"
+	"go-context-generator/internal/cli"
 )
 
 var iconBytes []byte
 
 func main() {
-	go func() {
-		w := app.NewWindow(
-			app.Title("Go Context Generator Pro"),
-			app.Maximized.Option(),
-		)
-
-		th := material.NewTheme()
-		th.Shaper = text.NewShaper(text.WithCollection(gofont.Collection()))
-		app := ui.NewApp(th)
-
-		if err := app.Run(w); err != nil {
-			log.Printf("Erro na aplicação: %v", err)
-			os.Exit(1)
-		}
-	}()
+	// Com argumentos, roda em modo linha de comando (sem janela)
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:]))
+	}
 
-	app.Main()
+	runGUI()
 }